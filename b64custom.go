@@ -0,0 +1,40 @@
+package data
+
+import (
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+)
+
+// NewCustomB64Encoder returns a base64 ByteEncoder with the given alphabet
+// of 64 distinct ascii characters, for systems that use neither the
+// standard nor the url-safe one. padding is the character used to pad the
+// output to a multiple of 4, or 0 for no padding.
+//
+// Unmarshal requires the same alphabet and padding, see also
+// StrictBase64Padding.
+func NewCustomB64Encoder(alphabet string, padding byte) (ByteEncoder, error) {
+	if len(alphabet) != 64 {
+		return nil, errors.Errorf("Alphabet must have 64 characters, got %d", len(alphabet))
+	}
+	var seen [256]bool
+	for i := 0; i < len(alphabet); i++ {
+		c := alphabet[i]
+		if c < 0x20 || c >= 0x7f {
+			return nil, errors.Errorf("Invalid alphabet character at %d: %q", i, c)
+		}
+		if seen[c] {
+			return nil, errors.Errorf("Duplicate alphabet character: %q", c)
+		}
+		seen[c] = true
+	}
+
+	enc := base64.NewEncoding(alphabet)
+	if padding == 0 {
+		return base64Encoder{enc.WithPadding(base64.NoPadding), 0}, nil
+	}
+	if padding < 0x20 || padding >= 0x7f || seen[padding] {
+		return nil, errors.Errorf("Invalid padding character: %q", padding)
+	}
+	return base64Encoder{enc.WithPadding(rune(padding)), padding}, nil
+}
@@ -0,0 +1,93 @@
+package data_test
+
+import (
+	"strings"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomB64Encoder(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	// the crypt(3) alphabet, and the standard one reversed
+	crypt, err := data.NewCustomB64Encoder("./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz", 0)
+	require.Nil(err, "%+v", err)
+	reversed, err := data.NewCustomB64Encoder("/+9876543210zyxwvutsrqponmlkjihgfedcbaZYXWVUTSRQPONMLKJIHGFEDCBA", '~')
+	require.Nil(err, "%+v", err)
+	// same as the built-in one
+	std, err := data.NewCustomB64Encoder("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_", '=')
+	require.Nil(err, "%+v", err)
+
+	cases := []struct {
+		encoder  data.ByteEncoder
+		input    []byte
+		expected string
+	}{
+		{crypt, []byte("foo"), "Naxj"},
+		{crypt, []byte("fo"), "Naw"},
+		{crypt, []byte{}, ""},
+		{reversed, []byte("foo"), "mZCQ"},
+		{reversed, []byte("fo"), "mZD~"},
+		{std, []byte("D!.3s"), "RCEuM3M="},
+		{std, []byte{0xfb, 0xff}, "-_8="},
+	}
+
+	for _, tc := range cases {
+		out, err := tc.encoder.Marshal(tc.input)
+		require.Nil(err, "%+v", err)
+		assert.Equal(`"`+tc.expected+`"`, string(out))
+
+		var back []byte
+		err = tc.encoder.Unmarshal(&back, out)
+		require.Nil(err, "%s: %+v", tc.expected, err)
+		assert.Equal(tc.input, back, tc.expected)
+	}
+
+	// std matches B64Encoder
+	b64, err := data.B64Encoder.Marshal([]byte("some bytes"))
+	require.Nil(err, "%+v", err)
+	out, err := std.Marshal([]byte("some bytes"))
+	require.Nil(err, "%+v", err)
+	assert.Equal(b64, out)
+
+	// unmarshal requires the same alphabet and padding
+	var back []byte
+	assert.NotNil(crypt.Unmarshal(&back, []byte(`"Zm-_"`)))
+	assert.NotNil(reversed.Unmarshal(&back, []byte(`"mZD="`)))
+	assert.NotNil(reversed.Unmarshal(&back, []byte(`"mZD"`)))
+	assert.NotNil(crypt.Unmarshal(&back, []byte(`"Naw=="`)))
+}
+
+func TestCustomB64EncoderErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	valid := "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	cases := []struct {
+		alphabet string
+		padding  byte
+	}{
+		// bad length
+		{"", 0},
+		{valid[:63], 0},
+		{valid + "!", 0},
+		// duplicates
+		{strings.Replace(valid, "_", "A", 1), 0},
+		{strings.Replace(valid, "a", "b", 1), '='},
+		// not printable ascii
+		{strings.Replace(valid, "_", "\n", 1), 0},
+		{strings.Replace(valid, "_", "\xff", 1), 0},
+		// bad padding
+		{valid, 'A'},
+		{valid, '-'},
+		{valid, '\n'},
+		{valid, 0xff},
+	}
+
+	for i, tc := range cases {
+		_, err := data.NewCustomB64Encoder(tc.alphabet, tc.padding)
+		assert.NotNil(err, "%d", i)
+	}
+}
@@ -0,0 +1,102 @@
+package data
+
+import (
+	"encoding/base32"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Base32CheckEncoder encodes bytes as unpadded base32 followed by a two
+// character check, meant for codes that humans read and type in.
+var Base32CheckEncoder = NewBase32CheckEncoder(2)
+
+const base32Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+
+var base32Raw = base32.NewEncoding(base32Alphabet).WithPadding(base32.NoPadding)
+
+// crcPolys are the generator polynomials for a check of 1, 2 or 3
+// characters (CRC-5-USB, CRC-10-ATM and CRC-15-CAN).
+//
+// As each character is one 5-bit burst in the checked bit stream, they
+// all detect every single mistyped character.
+var crcPolys = []uint32{0x05, 0x233, 0x4599}
+
+// NewBase32CheckEncoder returns a base32 encoder that appends checkLen
+// characters of checksum, and verifies them on decoding.
+//
+// This is not a cryptographic checksum: it guards against typos (every
+// single wrong character is detected), not against tampering.
+// Decoding is case-insensitive. checkLen must be between 1 and 3.
+func NewBase32CheckEncoder(checkLen int) ByteEncoder {
+	if checkLen < 1 || checkLen > len(crcPolys) {
+		panic(errors.Errorf("Invalid check length: %d", checkLen))
+	}
+	return base32CheckEncoder{checkLen}
+}
+
+// base32CheckEncoder implements ByteEncoder with base32 plus a crc suffix
+type base32CheckEncoder struct {
+	checkLen int
+}
+
+func (e base32CheckEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (e base32CheckEncoder) Unmarshal(dst *[]byte, src []byte) (err error) {
+	s, err := ParseJSONString(src)
+	if err != nil {
+		return err
+	}
+	s = strings.ToUpper(s)
+	if len(s) < e.checkLen {
+		return errors.Errorf("Too short for checksum: %s", s)
+	}
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(base32Alphabet, s[i]) < 0 {
+			return errors.Errorf("Encountered unknown character: %s", string(s[i]))
+		}
+	}
+	payload, check := s[:len(s)-e.checkLen], s[len(s)-e.checkLen:]
+	if e.check(payload) != check {
+		return errors.Errorf("Checksum mismatch: %s", s)
+	}
+	*dst, err = base32Raw.DecodeString(payload)
+	return errors.WithStack(err)
+}
+
+func (e base32CheckEncoder) Marshal(bytes []byte) ([]byte, error) {
+	s := base32Raw.EncodeToString(bytes)
+	return json.Marshal(s + e.check(s))
+}
+
+func (e base32CheckEncoder) EncodedLen(n int) int {
+	return base32Raw.EncodedLen(n) + e.checkLen
+}
+
+// check computes the crc over the 5-bit symbols of the base32 text s,
+// returned as checkLen base32 characters
+func (e base32CheckEncoder) check(s string) string {
+	size := uint(5 * e.checkLen)
+	poly, mask := crcPolys[e.checkLen-1], uint32(1)<<size-1
+	reg := mask
+	for i := 0; i < len(s); i++ {
+		sym := uint32(strings.IndexByte(base32Alphabet, s[i]))
+		for bit := 4; bit >= 0; bit-- {
+			in := (sym >> uint(bit)) & 1
+			top := (reg >> (size - 1)) & 1
+			reg = (reg << 1) & mask
+			if in^top == 1 {
+				reg ^= poly
+			}
+		}
+	}
+	res := make([]byte, e.checkLen)
+	for i := e.checkLen - 1; i >= 0; i-- {
+		res[i] = base32Alphabet[reg&0x1f]
+		reg >>= 5
+	}
+	return string(res)
+}
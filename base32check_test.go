@@ -0,0 +1,76 @@
+package data_test
+
+import (
+	"strings"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const base32Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+
+func TestBase32Check(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	inputs := [][]byte{
+		{},
+		{0x00},
+		[]byte("foo"),
+		[]byte("recovery code"),
+		{0xde, 0xad, 0xbe, 0xef, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55},
+	}
+
+	for checkLen := 1; checkLen <= 3; checkLen++ {
+		enc := data.NewBase32CheckEncoder(checkLen)
+		for _, input := range inputs {
+			d, err := enc.Marshal(input)
+			require.Nil(err, "%+v", err)
+			code := strings.Trim(string(d), `"`)
+
+			// valid codes round-trip, also when typed in lowercase
+			for _, c := range []string{code, strings.ToLower(code)} {
+				var output []byte
+				err = enc.Unmarshal(&output, []byte(`"`+c+`"`))
+				require.Nil(err, "%d %s: %+v", checkLen, c, err)
+				assert.Equal(input, output, "%d %s", checkLen, c)
+			}
+
+			// every single wrong character is detected
+			for i := range code {
+				for _, r := range base32Alphabet {
+					if byte(r) == code[i] {
+						continue
+					}
+					typo := code[:i] + string(r) + code[i+1:]
+					var output []byte
+					err = enc.Unmarshal(&output, []byte(`"`+typo+`"`))
+					assert.NotNil(err, "%d: %s -> %s", checkLen, code, typo)
+				}
+			}
+		}
+	}
+
+	// default uses a 2 character check
+	d, err := data.Base32CheckEncoder.Marshal([]byte("foo"))
+	require.Nil(err, "%+v", err)
+	assert.Equal(len(`"MZXW6"`)+2, len(d))
+
+	// other errors
+	errs := []string{
+		`0123`,      // not in quotes
+		`""`,        // no checksum
+		`"A"`,       // too short
+		`"MZXW6!!"`, // invalid chars
+		`"MZ0W6AA"`, // invalid chars
+	}
+	for _, input := range errs {
+		var output []byte
+		err := data.Base32CheckEncoder.Unmarshal(&output, []byte(input))
+		assert.NotNil(err, input)
+	}
+
+	assert.Panics(func() { data.NewBase32CheckEncoder(0) })
+	assert.Panics(func() { data.NewBase32CheckEncoder(4) })
+}
@@ -0,0 +1,84 @@
+package data
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// QRAlphanumEncoder encodes bytes as base45 (RFC 9285), which only uses
+// the 45 characters of the QR code alphanumeric mode, as done for the
+// EU digital covid certificates ("HC1:...").
+var QRAlphanumEncoder ByteEncoder = base45Encoder{}
+
+const base45Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// base45Encoder implements ByteEncoder, encoding every 2 bytes as 3
+// characters (and a trailing byte as 2), least significant first
+type base45Encoder struct{}
+
+func (e base45Encoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (_ base45Encoder) Unmarshal(dst *[]byte, src []byte) (err error) {
+	text, err := jsonText(src)
+	if err != nil {
+		return err
+	}
+	if len(text)%3 == 1 {
+		return errors.Errorf("Invalid base45 length: %d", len(text))
+	}
+	// check all chunks before allocating the result
+	for i := 0; i < len(text); i += 3 {
+		if _, err := base45Chunk(text, i); err != nil {
+			return err
+		}
+	}
+	res := make([]byte, 0, len(text)/3*2+len(text)%3/2)
+	for i := 0; i < len(text); i += 3 {
+		n, _ := base45Chunk(text, i)
+		if len(text)-i >= 3 {
+			res = append(res, byte(n>>8))
+		}
+		res = append(res, byte(n))
+	}
+	*dst = res
+	return nil
+}
+
+// base45Chunk decodes the value of the chunk of (up to) 3 characters at
+// text[i:], which must fit in 2 bytes (or 1 for a final 2 character chunk)
+func base45Chunk(text []byte, i int) (int, error) {
+	end := i + 3
+	if end > len(text) {
+		end = len(text)
+	}
+	n, weight := 0, 1
+	for j := i; j < end; j++ {
+		v := strings.IndexByte(base45Alphabet, text[j])
+		if v < 0 {
+			return 0, errors.Errorf("Encountered unknown character: %s", string(text[j]))
+		}
+		n += v * weight
+		weight *= 45
+	}
+	if end-i == 3 && n > 0xffff || end-i < 3 && n > 0xff {
+		return 0, errors.Errorf("Invalid base45 chunk: %s", text[i:end])
+	}
+	return n, nil
+}
+
+func (_ base45Encoder) Marshal(bytes []byte) ([]byte, error) {
+	res := make([]byte, 0, (len(bytes)+1)/2*3)
+	for i := 0; i+1 < len(bytes); i += 2 {
+		n := int(bytes[i])<<8 | int(bytes[i+1])
+		res = append(res, base45Alphabet[n%45], base45Alphabet[n/45%45], base45Alphabet[n/2025])
+	}
+	if len(bytes)%2 == 1 {
+		n := int(bytes[len(bytes)-1])
+		res = append(res, base45Alphabet[n%45], base45Alphabet[n/45])
+	}
+	return json.Marshal(string(res))
+}
@@ -0,0 +1,53 @@
+package data_test
+
+import (
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQRAlphanumEncoder(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	b45 := data.QRAlphanumEncoder
+	// test vectors from RFC 9285
+	cases := []struct {
+		input    []byte
+		expected string
+	}{
+		{[]byte("AB"), `"BB8"`},
+		{[]byte("Hello!!"), `"%69 VD92EX0"`},
+		{[]byte("base-45"), `"UJCLQE7W581"`},
+		{[]byte("ietf!"), `"QED8WEX0"`},
+		{[]byte{}, `""`},
+		{[]byte{0xff, 0xff}, `"FGW"`},
+		{[]byte{0xff}, `"U5"`},
+	}
+
+	for _, tc := range cases {
+		d, err := b45.Marshal(tc.input)
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.expected, string(d), "%s", tc.input)
+
+		var output []byte
+		err = b45.Unmarshal(&output, d)
+		require.Nil(err, "%s: %+v", d, err)
+		assert.Equal(tc.input, output, "%s", d)
+	}
+
+	errs := []string{
+		`"GGW"`,  // chunk > 65535
+		`"V5"`,   // trailing chunk > 255
+		`"BB8A"`, // invalid length
+		`"bb8"`,  // lowercase is not in the set
+		`"BB#"`,  // outside the set
+		`0123`,   // not in quotes
+	}
+	for _, input := range errs {
+		var output []byte
+		err := b45.Unmarshal(&output, []byte(input))
+		assert.NotNil(err, input)
+	}
+}
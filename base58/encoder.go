@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 
 	data "github.com/neatio-net/data-go"
-	"github.com/pkg/errors"
 )
 
 var (
@@ -12,8 +11,19 @@ var (
 	FlickrEncoder                  = base58Encoder{FlickrAlphabet}
 )
 
+func init() {
+	data.RegisterEncoder("base58", BTCEncoder)
+	data.RegisterEncoder("base58flickr", FlickrEncoder)
+}
+
 // base58Encoder implements ByteEncoder encoding the slice as
 // base58 url-safe encoding
+//
+// Decoding is strict: each value has exactly one valid encoding, so any
+// string that Unmarshal accepts re-encodes to exactly the same string.
+// This holds as every leading zero byte is one leading alphabet[0], and
+// any other character is a digit of the value, which may not be padded.
+// Unknown characters (including whitespace) are rejected.
 type base58Encoder struct {
 	alphabet string
 }
@@ -23,10 +33,9 @@ func (e base58Encoder) _assertByteEncoder() data.ByteEncoder {
 }
 
 func (e base58Encoder) Unmarshal(dst *[]byte, src []byte) (err error) {
-	var s string
-	err = json.Unmarshal(src, &s)
+	s, err := data.ParseJSONString(src)
 	if err != nil {
-		return errors.Wrap(err, "parse string")
+		return err
 	}
 	*dst, err = DecodeAlphabet(s, e.alphabet)
 	return err
@@ -1,11 +1,14 @@
 package base58_test
 
 import (
+	"math/rand"
+	"strings"
 	"testing"
 
 	data "github.com/neatio-net/data-go"
 	"github.com/neatio-net/data-go/base58"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEncoders(t *testing.T) {
@@ -35,3 +38,105 @@ func TestEncoders(t *testing.T) {
 		}
 	}
 }
+
+func TestRegistered(t *testing.T) {
+	assert := assert.New(t)
+
+	enc, ok := data.EncoderByName("base58")
+	assert.True(ok)
+	assert.Equal(base58.BTCEncoder, enc)
+	enc, ok = data.EncoderByName("base58flickr")
+	assert.True(ok)
+	assert.Equal(base58.FlickrEncoder, enc)
+}
+
+func TestMultibase(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	// the examples from the multibase spec
+	cases := []struct {
+		prefix   byte
+		expected string
+	}{
+		{'z', `"z7paNL19xttacUY"`},
+		{'Z', `"Z7Pznk19XTTzBtx"`},
+	}
+
+	for _, tc := range cases {
+		enc := data.MultibaseEncoder{Default: tc.prefix}
+		out, err := enc.Marshal([]byte("yes mani !"))
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.expected, string(out))
+
+		var back []byte
+		err = data.MultibaseEncoder{}.Unmarshal(&back, out)
+		if assert.Nil(err, "%+v", err) {
+			assert.Equal("yes mani !", string(back))
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cases := []struct {
+		encoder data.ByteEncoder
+		input   string
+		valid   bool
+	}{
+		{base58.BTCEncoder, `"3mJr7AoUXx2Wqd"`, true},
+		// every leading zero byte is one leading '1'
+		{base58.BTCEncoder, `"1"`, true},
+		{base58.BTCEncoder, `"111z"`, true},
+		{base58.FlickrEncoder, `"111Z"`, true},
+		{base58.BTCEncoder, `""`, true},
+		// no whitespace or other characters
+		{base58.BTCEncoder, `" 3mJr7AoUXx2Wqd"`, false},
+		{base58.BTCEncoder, `"3mJr7AoUXx2Wqd\n"`, false},
+		{base58.BTCEncoder, `"0003mJr"`, false},
+	}
+
+	for _, tc := range cases {
+		var output []byte
+		err := tc.encoder.Unmarshal(&output, []byte(tc.input))
+		if !tc.valid {
+			assert.NotNil(err, tc.input)
+			continue
+		}
+		require.Nil(err, "%s: %+v", tc.input, err)
+		d, err := tc.encoder.Marshal(output)
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.input, string(d))
+	}
+
+	// anything we accept re-encodes to the identical string
+	r := rand.New(rand.NewSource(42))
+	encoders := []struct {
+		encoder  data.ByteEncoder
+		alphabet string
+	}{
+		{base58.BTCEncoder, base58.BTCAlphabet},
+		{base58.FlickrEncoder, base58.FlickrAlphabet},
+	}
+	for _, e := range encoders {
+		enc, alphabet := e.encoder, e.alphabet
+		for i := 0; i < 1000; i++ {
+			var s strings.Builder
+			for j := r.Intn(20); j > 0; j-- {
+				// bias to leading zeros
+				if r.Intn(3) == 0 {
+					s.WriteByte(alphabet[0])
+				} else {
+					s.WriteByte(alphabet[r.Intn(58)])
+				}
+			}
+			input := `"` + s.String() + `"`
+			var output []byte
+			err := enc.Unmarshal(&output, []byte(input))
+			require.Nil(err, "%s: %+v", input, err)
+			d, err := enc.Marshal(output)
+			require.Nil(err, "%+v", err)
+			require.Equal(input, string(d))
+		}
+	}
+}
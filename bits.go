@@ -0,0 +1,53 @@
+package data
+
+import "fmt"
+
+// TestBit returns true if bit i is set, counting from the most significant
+// bit of the first byte: bit 0 is b[0]&0x80, bit 7 is b[0]&0x01 and bit 8
+// is b[1]&0x80. Bits past the end are not set, so it returns false for
+// them. A negative i panics, like an index out of range.
+func (b Bytes) TestBit(i int) bool {
+	checkBitIndex(i)
+	if i/8 >= len(b) {
+		return false
+	}
+	return b[i/8]&bitMask(i) != 0
+}
+
+// SetBit returns a copy of b with bit i (counted as in TestBit) set. If i is
+// past the end, the copy is grown with zero bytes to hold it, so new flags
+// can be added to old values. A negative i panics.
+func (b Bytes) SetBit(i int) Bytes {
+	checkBitIndex(i)
+	size := len(b)
+	if i/8 >= size {
+		size = i/8 + 1
+	}
+	res := make(Bytes, size)
+	copy(res, b)
+	res[i/8] |= bitMask(i)
+	return res
+}
+
+// ClearBit returns a copy of b with bit i (counted as in TestBit) cleared.
+// Bits past the end are not set already, so the copy is never grown. A
+// negative i panics.
+func (b Bytes) ClearBit(i int) Bytes {
+	checkBitIndex(i)
+	res := append(Bytes{}, b...)
+	if i/8 < len(res) {
+		res[i/8] &^= bitMask(i)
+	}
+	return res
+}
+
+// bitMask returns the mask for bit i within its byte, msb first
+func bitMask(i int) byte {
+	return 0x80 >> uint(i%8)
+}
+
+func checkBitIndex(i int) {
+	if i < 0 {
+		panic(fmt.Sprintf("data: invalid bit index %d", i))
+	}
+}
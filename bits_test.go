@@ -0,0 +1,65 @@
+package data_test
+
+import (
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBits(t *testing.T) {
+	assert := assert.New(t)
+
+	flags := data.Bytes{0x81, 0x40}
+	set := map[int]bool{0: true, 7: true, 9: true}
+	for i := 0; i < 24; i++ {
+		assert.Equal(set[i], flags.TestBit(i), "bit %d", i)
+	}
+	assert.False(data.Bytes(nil).TestBit(0))
+
+	cases := []struct {
+		input   data.Bytes
+		bit     int
+		set     data.Bytes
+		cleared data.Bytes
+	}{
+		{data.Bytes{0x00}, 0, data.Bytes{0x80}, data.Bytes{0x00}},
+		{data.Bytes{0xff}, 0, data.Bytes{0xff}, data.Bytes{0x7f}},
+		{data.Bytes{0x00}, 7, data.Bytes{0x01}, data.Bytes{0x00}},
+		{data.Bytes{0xff}, 7, data.Bytes{0xff}, data.Bytes{0xfe}},
+		// across byte boundaries
+		{data.Bytes{0x81, 0x40}, 8, data.Bytes{0x81, 0xc0}, data.Bytes{0x81, 0x40}},
+		{data.Bytes{0x81, 0x40}, 9, data.Bytes{0x81, 0x40}, data.Bytes{0x81, 0x00}},
+		{data.Bytes{0x01, 0x80}, 15, data.Bytes{0x01, 0x81}, data.Bytes{0x01, 0x80}},
+		// out of range grows on set, and is a no-op on clear
+		{data.Bytes{0x81}, 8, data.Bytes{0x81, 0x80}, data.Bytes{0x81}},
+		{data.Bytes{0x81}, 23, data.Bytes{0x81, 0x00, 0x01}, data.Bytes{0x81}},
+		{nil, 3, data.Bytes{0x10}, data.Bytes{}},
+	}
+
+	for _, tc := range cases {
+		orig := append(data.Bytes{}, tc.input...)
+
+		set := tc.input.SetBit(tc.bit)
+		assert.Equal(tc.set, set, "set %X bit %d", tc.input, tc.bit)
+		assert.True(set.TestBit(tc.bit))
+
+		cleared := tc.input.ClearBit(tc.bit)
+		assert.Equal(tc.cleared, cleared, "clear %X bit %d", tc.input, tc.bit)
+		assert.False(cleared.TestBit(tc.bit))
+
+		// the input is never modified
+		assert.Equal(orig, append(data.Bytes{}, tc.input...))
+	}
+
+	// the copies don't alias
+	b := data.Bytes{0x00, 0x00}
+	b.SetBit(0)[1] = 0xff
+	b.ClearBit(0)[1] = 0xff
+	assert.Equal(data.Bytes{0x00, 0x00}, b)
+
+	// negative indices panic
+	assert.Panics(func() { b.TestBit(-1) })
+	assert.Panics(func() { b.SetBit(-1) })
+	assert.Panics(func() { b.ClearBit(-8) })
+}
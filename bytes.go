@@ -1,6 +1,7 @@
 package data
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -17,12 +18,36 @@ import (
 // BTCEncoder and FlickrEncoder that use base58 variants in
 // github.com/neatio-net/data-go/base58
 var (
-	Encoder       ByteEncoder = hexEncoder{}
-	HexEncoder                = hexEncoder{}
-	B64Encoder                = base64Encoder{base64.URLEncoding}
-	RawB64Encoder             = base64Encoder{base64.RawURLEncoding}
+	Encoder       ByteEncoder = HexFormat{}
+	HexEncoder                = HexFormat{}
+	B64Encoder                = base64Encoder{base64.URLEncoding, '='}
+	RawB64Encoder             = base64Encoder{base64.RawURLEncoding, 0}
 )
 
+// StrictBase64Padding controls how the padded base64 encoders (B64Encoder,
+// and custom ones with padding) treat values without the trailing padding.
+// If true (the default), Unmarshal requires exactly the padding Marshal
+// produces. Set it to false to accept values with missing padding as well,
+// for data written by systems that strip it.
+//
+// Like Encoder, set it in main, not while decoding. The unpadded encoders
+// never accept padding, either way.
+var StrictBase64Padding = true
+
+// SaveEncoder remembers the current Encoder, and returns a function that
+// restores it. This is mainly for tests that change the global:
+//
+//	defer data.SaveEncoder()()
+//	data.Encoder = data.B64Encoder
+//
+// See also datatest.WithEncoder.
+func SaveEncoder() func() {
+	saved := Encoder
+	return func() {
+		Encoder = saved
+	}
+}
+
 // Bytes is a special byte slice that allows us to control the
 // serialization format per app.
 //
@@ -31,12 +56,65 @@ var (
 type Bytes []byte
 
 func (b Bytes) MarshalJSON() ([]byte, error) {
-	return Encoder.Marshal(b)
+	return marshalObserved(currentEncoder(), b)
 }
 
+// UnmarshalJSON decodes a string with the global Encoder. For interop with
+// tools that dump raw byte arrays, it also accepts a json array of integers
+// between 0 and 255, like [26, 43].
 func (b *Bytes) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		return b.unmarshalArray(trimmed)
+	}
 	ref := (*[]byte)(b)
-	return Encoder.Unmarshal(ref, data)
+	return unmarshalHooked(Encoder, ref, data)
+}
+
+func (b *Bytes) unmarshalArray(data []byte) error {
+	res, err := parseByteArray(data)
+	err = formatHook("array", len(data), res, err)
+	if err != nil {
+		return err
+	}
+	*b = res
+	return nil
+}
+
+// parseByteArray parses a json array of integers between 0 and 255
+func parseByteArray(data []byte) ([]byte, error) {
+	var ints []int
+	err := json.Unmarshal(data, &ints)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse byte array")
+	}
+	res := make([]byte, len(ints))
+	for i, v := range ints {
+		if v < 0 || v > 255 {
+			return nil, errors.Errorf("Byte out of range at index %d: %d", i, v)
+		}
+		res[i] = byte(v)
+	}
+	return res, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, returning the raw
+// bytes without any encoding
+func (b Bytes) MarshalBinary() ([]byte, error) {
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, storing a copy of
+// the raw bytes. nil stays nil.
+func (b *Bytes) UnmarshalBinary(data []byte) error {
+	if err := formatHook("binary", len(data), data, nil); err != nil {
+		return err
+	}
+	if data == nil {
+		*b = nil
+		return nil
+	}
+	*b = append(Bytes{}, data...)
+	return nil
 }
 
 // Allow it to fulfill various interfaces in light-client, etc...
@@ -63,39 +141,175 @@ func (b Bytes) Bytes() []byte {
 //     copy(d[:], ref)
 //     return err
 //   }
+//
+// Unmarshal must store a newly allocated slice in dst, never one that
+// shares memory with src, as callers may reuse their input buffer. All
+// encoders in this package guarantee this, WithCopyOutput adapts those
+// that don't.
 type ByteEncoder interface {
 	Marshal(bytes []byte) ([]byte, error)
 	Unmarshal(dst *[]byte, src []byte) error
 }
 
-// hexEncoder implements ByteEncoder encoding the slice as a hexidecimal
-// string
-type hexEncoder struct{}
+// LenEncoder is implemented by ByteEncoders whose output size only depends
+// on the input size, so it can be computed up front.
+type LenEncoder interface {
+	// EncodedLen returns the length of the encoded string (without the json
+	// quotes) for n bytes of input
+	EncodedLen(n int) int
+}
+
+// EncodedLen returns the length of the encoded string (without the json
+// quotes) that enc produces for n bytes of input, or false if enc doesn't
+// implement LenEncoder.
+//
+// HexFormat, B64Encoder, RawB64Encoder and base32 check encoders support
+// this. Encoders whose output depends on the content, like percent, base58
+// or the word encoders, do not.
+func EncodedLen(enc ByteEncoder, n int) (int, bool) {
+	le, ok := enc.(LenEncoder)
+	if !ok {
+		return 0, false
+	}
+	return le.EncodedLen(n), true
+}
+
+// HexFormat implements ByteEncoder encoding the slice as a hexidecimal
+// string.
+//
+// The zero value (used for HexEncoder) produces uppercase hex without any
+// prefix or separators. Set Lower for lowercase output, Prefix to add eg.
+// "0x", and Separator and GroupSize to insert Separator after every
+// GroupSize bytes.
+//
+// Unmarshal accepts hex in any case, with or without Prefix (also in any
+// case), and ignores all occurrences of Separator (which therefore should
+// not contain any hex digits). Set StrictCase to only accept the case that
+// Marshal produces, for both the digits and the Prefix.
+type HexFormat struct {
+	Lower      bool
+	Prefix     string
+	Separator  string
+	GroupSize  int
+	StrictCase bool
+}
 
-func (h hexEncoder) _assertByteEncoder() ByteEncoder {
+func (h HexFormat) _assertByteEncoder() ByteEncoder {
 	return h
 }
 
-func (_ hexEncoder) Unmarshal(dst *[]byte, src []byte) (err error) {
-	var s string
-	err = json.Unmarshal(src, &s)
+func (h HexFormat) Unmarshal(dst *[]byte, src []byte) (err error) {
+	res, err := h.appendDecode(nil, src)
 	if err != nil {
-		return errors.Wrap(err, "parse string")
+		return err
 	}
-	// and interpret that string as hex
-	*dst, err = hex.DecodeString(s)
-	return err
+	*dst = res
+	return nil
 }
 
-func (_ hexEncoder) Marshal(bytes []byte) ([]byte, error) {
-	s := strings.ToUpper(hex.EncodeToString(bytes))
-	return json.Marshal(s)
+// appendDecode implements appendDecoder, see Unmarshal
+func (h HexFormat) appendDecode(buf []byte, src []byte) ([]byte, error) {
+	if err := h.checkSeparator(); err != nil {
+		return nil, err
+	}
+	text, err := jsonText(src)
+	if err != nil {
+		return nil, err
+	}
+	if h.Prefix != "" && len(text) >= len(h.Prefix) &&
+		h.equalCase(string(text[:len(h.Prefix)]), h.Prefix) {
+		text = text[len(h.Prefix):]
+	}
+
+	// check all of it before allocating the result
+	digits := 0
+	for i := 0; i < len(text); i++ {
+		if h.isSeparatorAt(text, i) {
+			i += len(h.Separator) - 1
+			continue
+		}
+		c := text[i]
+		if _, ok := unhex(c); !ok {
+			return nil, hex.InvalidByteError(c)
+		}
+		if h.StrictCase && (h.Lower && 'A' <= c && c <= 'F' || !h.Lower && 'a' <= c && c <= 'f') {
+			return nil, errors.Errorf("Invalid case of hex digit: %s", string(c))
+		}
+		digits++
+	}
+	if digits%2 != 0 {
+		return nil, hex.ErrLength
+	}
+
+	res := growBuffer(buf, digits/2)
+	var hi byte
+	odd := false
+	for i := 0; i < len(text); i++ {
+		if h.isSeparatorAt(text, i) {
+			i += len(h.Separator) - 1
+			continue
+		}
+		v, _ := unhex(text[i])
+		if odd {
+			res = append(res, hi<<4|v)
+		}
+		hi, odd = v, !odd
+	}
+	return res, nil
+}
+
+// isSeparatorAt returns true if the Separator starts at text[i]
+func (h HexFormat) isSeparatorAt(text []byte, i int) bool {
+	return h.Separator != "" && len(text)-i >= len(h.Separator) &&
+		string(text[i:i+len(h.Separator)]) == h.Separator
+}
+
+func (h HexFormat) EncodedLen(n int) int {
+	size := len(h.Prefix) + 2*n
+	if h.GroupSize > 0 && n > h.GroupSize {
+		size += (n - 1) / h.GroupSize * len(h.Separator)
+	}
+	return size
+}
+
+// equalCase compares a and b, ignoring case unless StrictCase is set
+func (h HexFormat) equalCase(a, b string) bool {
+	if h.StrictCase {
+		return a == b
+	}
+	return strings.EqualFold(a, b)
+}
+
+// checkSeparator rejects separators with hex digits, which Unmarshal
+// could not tell apart from the value anymore
+func (h HexFormat) checkSeparator() error {
+	if strings.ContainsAny(h.Separator, "0123456789abcdefABCDEF") {
+		return errors.Errorf("Separator contains hex digits: %q", h.Separator)
+	}
+	return nil
+}
+
+// Marshal always produces 2 hex digits per byte, plus the Prefix and
+// Separators, so the digits have the even length Unmarshal requires.
+func (h HexFormat) Marshal(bytes []byte) ([]byte, error) {
+	if err := h.checkSeparator(); err != nil {
+		return nil, err
+	}
+	s := Bytes(bytes).HexGrouped(h.GroupSize, h.Separator)
+	if h.Lower {
+		s = strings.ToLower(s)
+	}
+	return json.Marshal(h.Prefix + s)
 }
 
 // base64Encoder implements ByteEncoder encoding the slice as
 // base64 url-safe encoding
+//
+// It implements LenEncoder through the embedded base64.Encoding. pad is
+// the padding character, or 0 if the encoding has none.
 type base64Encoder struct {
 	*base64.Encoding
+	pad byte
 }
 
 func (e base64Encoder) _assertByteEncoder() ByteEncoder {
@@ -103,13 +317,123 @@ func (e base64Encoder) _assertByteEncoder() ByteEncoder {
 }
 
 func (e base64Encoder) Unmarshal(dst *[]byte, src []byte) (err error) {
-	var s string
-	err = json.Unmarshal(src, &s)
+	res, err := e.appendDecode(nil, src)
+	if err != nil {
+		return err
+	}
+	*dst = res
+	return nil
+}
+
+// appendDecode implements appendDecoder, see Unmarshal
+func (e base64Encoder) appendDecode(buf []byte, src []byte) ([]byte, error) {
+	text, err := jsonText(src)
+	if err != nil {
+		return nil, err
+	}
+	if !StrictBase64Padding && e.pad != 0 {
+		if !bytes.ContainsAny(text, "\r\n") {
+			if missing := (4 - len(text)%4) % 4; missing == 1 || missing == 2 {
+				return e.appendDecodeUnpadded(buf, text, missing)
+			}
+		}
+		text = e.repairPadding(text)
+	}
+	err = e.validate(text)
 	if err != nil {
-		return errors.Wrap(err, "parse string")
+		return nil, err
+	}
+	res := growBuffer(buf, e.DecodedLen(len(text)))
+	n, err := e.Decode(res[len(res):cap(res)], text)
+	if err != nil {
+		return nil, err
+	}
+	return res[:len(res)+n], nil
+}
+
+// appendDecodeUnpadded decodes text that lacks the last missing padding
+// characters, without copying it: the full blocks are validated and decoded
+// in place, and only the last one is padded, in a small buffer.
+func (e base64Encoder) appendDecodeUnpadded(buf []byte, text []byte, missing int) ([]byte, error) {
+	split := len(text) - (4 - missing)
+	head := text[:split]
+	// padding is only allowed in the last block
+	if i := bytes.IndexByte(head, e.pad); i >= 0 {
+		return nil, base64.CorruptInputError(int64(i))
+	}
+	err := e.validate(head)
+	if err != nil {
+		return nil, err
+	}
+	var last [4]byte
+	copy(last[:], text[split:])
+	for i := 4 - missing; i < 4; i++ {
+		last[i] = e.pad
+	}
+	var tail [3]byte
+	m, err := e.Decode(tail[:], last[:])
+	if err != nil {
+		if offset, ok := err.(base64.CorruptInputError); ok {
+			return nil, base64.CorruptInputError(int64(split) + int64(offset))
+		}
+		return nil, err
+	}
+	res := growBuffer(buf, len(head)/4*3+m)
+	n, err := e.Decode(res[len(res):cap(res)], head)
+	if err != nil {
+		return nil, err
+	}
+	return append(res[:len(res)+n], tail[:m]...), nil
+}
+
+// repairPadding adds the padding missing from the end of text, if any. It
+// returns a new slice then, as text may be the caller's input. This is
+// only used for text with newlines, that appendDecodeUnpadded can't split
+// into blocks.
+func (e base64Encoder) repairPadding(text []byte) []byte {
+	if e.pad == 0 {
+		return text
+	}
+	n := len(text) - bytes.Count(text, []byte{'\n'}) - bytes.Count(text, []byte{'\r'})
+	missing := (4 - n%4) % 4
+	// a single character over a full block can't be fixed
+	if missing == 0 || missing == 3 {
+		return text
+	}
+	res := make([]byte, len(text), len(text)+missing)
+	copy(res, text)
+	for i := 0; i < missing; i++ {
+		res = append(res, e.pad)
+	}
+	return res
+}
+
+// validate decodes text one block at a time into a small buffer, to find
+// errors before allocating the result. Newlines (which the decoder skips)
+// would misalign the blocks, so then it leaves all checks to Decode.
+func (e base64Encoder) validate(text []byte) error {
+	if bytes.ContainsAny(text, "\r\n") {
+		return nil
+	}
+	var buf [3]byte
+	for i := 0; i < len(text); i += 4 {
+		end := i + 4
+		if end > len(text) {
+			end = len(text)
+		}
+		n, err := e.Decode(buf[:], text[i:end])
+		if err != nil {
+			if offset, ok := err.(base64.CorruptInputError); ok {
+				return base64.CorruptInputError(int64(i) + int64(offset))
+			}
+			return err
+		}
+		// only the last block may be short (padded)
+		if n < 3 && end < len(text) {
+			return base64.CorruptInputError(int64(end))
+		}
 	}
-	*dst, err = e.DecodeString(s)
-	return err
+	return nil
 }
 
 func (e base64Encoder) Marshal(bytes []byte) ([]byte, error) {
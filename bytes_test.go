@@ -1,7 +1,13 @@
 package data_test
 
 import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
 	"encoding/json"
+	"math/rand"
+	"runtime"
+	"strings"
 	"testing"
 
 	data "github.com/neatio-net/data-go"
@@ -62,6 +68,130 @@ func TestEncoders(t *testing.T) {
 	}
 }
 
+func TestHexFormat(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	input := []byte{0x1a, 0x2b, 0x3c, 0x4d, 0x5e}
+	cases := []struct {
+		format   data.HexFormat
+		expected string
+	}{
+		// zero value is the default HexEncoder
+		{data.HexFormat{}, `"1A2B3C4D5E"`},
+		{data.HexFormat{Lower: true}, `"1a2b3c4d5e"`},
+		{data.HexFormat{Prefix: "0x"}, `"0x1A2B3C4D5E"`},
+		{data.HexFormat{Lower: true, Prefix: "0x"}, `"0x1a2b3c4d5e"`},
+		{data.HexFormat{Separator: " ", GroupSize: 2}, `"1A2B 3C4D 5E"`},
+		{data.HexFormat{Separator: ":", GroupSize: 1, Lower: true}, `"1a:2b:3c:4d:5e"`},
+		{data.HexFormat{Prefix: "0x", Separator: "_", GroupSize: 4}, `"0x1A2B3C4D_5E"`},
+		// no groups without a GroupSize
+		{data.HexFormat{Separator: " "}, `"1A2B3C4D5E"`},
+	}
+
+	for _, tc := range cases {
+		d, err := tc.format.Marshal(input)
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.expected, string(d), "%#v", tc.format)
+
+		var output []byte
+		err = tc.format.Unmarshal(&output, d)
+		require.Nil(err, "%#v: %+v", tc.format, err)
+		assert.Equal(input, output, "%#v", tc.format)
+	}
+	assert.Equal(data.HexFormat{}, data.HexEncoder)
+
+	// decoding accepts any case, and the prefix is optional
+	format := data.HexFormat{Prefix: "0x", Separator: " ", GroupSize: 2}
+	for _, in := range []string{`"0x1a2B 3c4D 5e"`, `"0X1A2B3C4D5E"`, `"1a2b 3c4d5e"`} {
+		var output []byte
+		err := format.Unmarshal(&output, []byte(in))
+		require.Nil(err, "%s: %+v", in, err)
+		assert.Equal(input, output, in)
+	}
+
+	// errors
+	for _, in := range []string{`"0x1a2b 3c4"`, `"0x0x1a"`, `"1a-2b"`, `0123`} {
+		var output []byte
+		err := format.Unmarshal(&output, []byte(in))
+		assert.NotNil(err, in)
+	}
+}
+
+func TestHexFormatStrictCase(t *testing.T) {
+	assert := assert.New(t)
+
+	strictLower := data.HexFormat{Lower: true, StrictCase: true}
+	strictUpper := data.HexFormat{StrictCase: true}
+	strictPrefix := data.HexFormat{Lower: true, Prefix: "0x", StrictCase: true}
+	cases := []struct {
+		encoder data.ByteEncoder
+		input   string
+		valid   bool
+	}{
+		// default is lenient
+		{data.HexEncoder, `"1a2b3c"`, true},
+		{data.HexEncoder, `"1A2B3C"`, true},
+		{data.HexFormat{Lower: true}, `"1A2b3C"`, true},
+		// strict lowercase
+		{strictLower, `"1a2b3c"`, true},
+		{strictLower, `"123456"`, true},
+		{strictLower, `"1A2b3c"`, false},
+		{strictLower, `"1A2B3C"`, false},
+		// strict uppercase
+		{strictUpper, `"1A2B3C"`, true},
+		{strictUpper, `"1A2B3c"`, false},
+		// prefix as well
+		{strictPrefix, `"0x1a2b"`, true},
+		{strictPrefix, `"0X1a2b"`, false},
+		{strictPrefix, `"0x1A2b"`, false},
+	}
+
+	for _, tc := range cases {
+		var output []byte
+		err := tc.encoder.Unmarshal(&output, []byte(tc.input))
+		if tc.valid {
+			assert.Nil(err, "%#v %s: %+v", tc.encoder, tc.input, err)
+		} else {
+			assert.NotNil(err, "%#v %s", tc.encoder, tc.input)
+		}
+	}
+}
+
+func TestEncodedLen(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	encoders := []data.ByteEncoder{
+		data.HexEncoder,
+		data.HexFormat{Prefix: "0x", Separator: " - ", GroupSize: 3},
+		data.HexFormat{Separator: ":", GroupSize: 1},
+		data.B64Encoder,
+		data.RawB64Encoder,
+		data.Base32CheckEncoder,
+		data.NewBase32CheckEncoder(3),
+	}
+
+	for _, enc := range encoders {
+		for _, n := range []int{0, 1, 2, 3, 4, 5, 6, 7, 31, 32, 33, 100} {
+			input := make([]byte, n)
+			d, err := enc.Marshal(input)
+			require.Nil(err, "%+v", err)
+			size, ok := data.EncodedLen(enc, n)
+			require.True(ok, "%#v", enc)
+			assert.Equal(len(d)-2, size, "%#v: %d", enc, n)
+		}
+	}
+
+	// hex is 2n, base64 padded is 4*ceil(n/3)
+	size, _ := data.EncodedLen(data.HexEncoder, 10)
+	assert.Equal(20, size)
+	size, _ = data.EncodedLen(data.B64Encoder, 10)
+	assert.Equal(16, size)
+
+	// depends on the content
+	_, ok := data.EncodedLen(data.PercentEncoder, 10)
+	assert.False(ok)
+}
+
 // BData can be encoded/decoded
 type BData struct {
 	Count int
@@ -107,6 +237,104 @@ func TestBytes(t *testing.T) {
 	}
 }
 
+// binaryRoundTrip only knows about the encoding interfaces
+func binaryRoundTrip(in encoding.BinaryMarshaler, out encoding.BinaryUnmarshaler) error {
+	d, err := in.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return out.UnmarshalBinary(d)
+}
+
+func TestBinaryMarshaler(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cases := []data.Bytes{
+		{0x1a, 0x2b, 0x3c},
+		{0x00},
+		{},
+		nil,
+	}
+
+	for i, tc := range cases {
+		// identity, no encoding
+		d, err := tc.MarshalBinary()
+		require.Nil(err, "%d: %+v", i, err)
+		assert.Equal([]byte(tc), d, "%d", i)
+
+		var out data.Bytes
+		err = binaryRoundTrip(tc, &out)
+		require.Nil(err, "%d: %+v", i, err)
+		// keeps nil and empty apart
+		assert.Equal(tc, out, "%d", i)
+	}
+
+	// stores a copy
+	raw := []byte{0x01, 0x02}
+	var out data.Bytes
+	require.Nil(out.UnmarshalBinary(raw))
+	raw[0] = 0xff
+	assert.Equal(data.Bytes{0x01, 0x02}, out)
+
+	// gob uses the binary interfaces
+	in := BData{Count: 7, Data: data.Bytes("D!.3s")}
+	var buf bytes.Buffer
+	require.Nil(gob.NewEncoder(&buf).Encode(in))
+	parsed := BData{}
+	require.Nil(gob.NewDecoder(&buf).Decode(&parsed))
+	assert.Equal(in, parsed)
+}
+
+func TestBytesFromArray(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	data.Encoder = data.HexEncoder
+	cases := []struct {
+		str, array string
+		expected   data.Bytes
+	}{
+		{`"1A2B"`, `[26, 43]`, data.Bytes{0x1a, 0x2b}},
+		{`"00FF"`, ` [0,255] `, data.Bytes{0x00, 0xff}},
+		{`""`, `[]`, data.Bytes{}},
+	}
+
+	for _, tc := range cases {
+		var fromStr, fromArray data.Bytes
+		err := json.Unmarshal([]byte(tc.str), &fromStr)
+		require.Nil(err, "%+v", err)
+		err = json.Unmarshal([]byte(tc.array), &fromArray)
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.expected, fromStr, tc.str)
+		assert.Equal(tc.expected, fromArray, tc.array)
+
+		// marshal is still the encoded string
+		d, err := json.Marshal(fromArray)
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.str, string(d))
+	}
+
+	// also inside of structs
+	parsed := BData{}
+	err := json.Unmarshal([]byte(`{"Count": 2, "Data": [68, 33]}`), &parsed)
+	require.Nil(err, "%+v", err)
+	assert.Equal(data.Bytes("D!"), parsed.Data)
+
+	errs := []string{
+		`[256]`,
+		`[-1]`,
+		`[1, 2.5]`,
+		`[1, "2"]`,
+		`[1, 2`,
+		`[99999999999999999999]`,
+	}
+	for _, input := range errs {
+		b := data.Bytes("keep")
+		err := json.Unmarshal([]byte(input), &b)
+		assert.NotNil(err, input)
+		assert.Equal(data.Bytes("keep"), b, input)
+	}
+}
+
 /*** this is example code for the byte array ***/
 
 type Dings [5]byte
@@ -166,3 +394,281 @@ func TestByteArray(t *testing.T) {
 	require.NotNil(err)
 	assert.Equal(ding, parsed)
 }
+
+// TestUnmarshalNoAlias checks no decoder returns memory that is shared
+// with its input, by overwriting the input after decoding.
+func TestUnmarshalNoAlias(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	key := bytes.Repeat([]byte{0x42}, 16)
+	aes, err := data.WithAESGCM(key, data.B64Encoder)
+	require.Nil(err, "%+v", err)
+
+	value := []byte("some value to decode, and then some")
+	encoders := []data.ByteEncoder{
+		data.HexEncoder,
+		data.HexFormat{Lower: true, Prefix: "0x", Separator: ":", GroupSize: 2},
+		data.B64Encoder,
+		data.RawB64Encoder,
+		data.PercentEncoder,
+		data.Base32CheckEncoder,
+		data.QRAlphanumEncoder,
+		data.RFC1924Encoder,
+		data.WithTag([]byte("tag"), data.HexEncoder),
+		data.WithLenientQuotes(data.B64Encoder),
+		data.WithBytesObject(data.HexEncoder),
+		aes,
+	}
+
+	overwrite := func(buf []byte) {
+		for i := range buf {
+			buf[i] = 'x'
+		}
+	}
+
+	for i, enc := range encoders {
+		buf, err := enc.Marshal(value)
+		require.Nil(err, "%d: %+v", i, err)
+		var out []byte
+		err = enc.Unmarshal(&out, buf)
+		require.Nil(err, "%d: %+v", i, err)
+		overwrite(buf)
+		assert.Equal(value, out, "%d", i)
+	}
+
+	// other decoding paths
+	arr := []byte(`[1, 2, 3]`)
+	var fromArr data.Bytes
+	require.Nil(json.Unmarshal(arr, &fromArr))
+	overwrite(arr)
+	assert.Equal(data.Bytes{1, 2, 3}, fromArr)
+
+	raw := []byte{1, 2, 3}
+	var fromBinary data.Bytes
+	require.Nil(fromBinary.UnmarshalBinary(raw))
+	overwrite(raw)
+	assert.Equal(data.Bytes{1, 2, 3}, fromBinary)
+
+	wire := data.Bytes{1, 2, 3}.MarshalWire()
+	fromWire, _, err := data.UnmarshalWire(wire)
+	require.Nil(err, "%+v", err)
+	overwrite(wire)
+	assert.Equal(data.Bytes{1, 2, 3}, fromWire)
+
+	tagged := []byte(`{"enc": "hex", "val": "010203"}`)
+	var fromTagged data.TaggedBytes
+	require.Nil(json.Unmarshal(tagged, &fromTagged))
+	overwrite(tagged)
+	assert.Equal(data.TaggedBytes{1, 2, 3}, fromTagged)
+}
+
+// checkHexInvariant asserts the hex digits in out (ignoring the quotes,
+// prefix and separators) are exactly 2 per input byte, and decode again
+func checkHexInvariant(t *testing.T, h data.HexFormat, input, out []byte) {
+	s := strings.Trim(string(out), `"`)
+	s = strings.TrimPrefix(s, h.Prefix)
+	if h.Separator != "" {
+		s = strings.Replace(s, h.Separator, "", -1)
+	}
+	if len(s) != 2*len(input) {
+		t.Fatalf("%#v: %d hex digits for %d bytes", h, len(s), len(input))
+	}
+	var back []byte
+	if err := h.Unmarshal(&back, out); err != nil {
+		t.Fatalf("%#v: %+v", h, err)
+	}
+	if !bytes.Equal(input, back) {
+		t.Fatalf("%#v: decoded %X, expected %X", h, back, input)
+	}
+}
+
+var hexFormats = []data.HexFormat{
+	data.HexEncoder,
+	{Lower: true},
+	{Prefix: "0x", StrictCase: true},
+	{Separator: " ", GroupSize: 2},
+	{Lower: true, Prefix: "0X", Separator: ":", GroupSize: 1},
+	{Separator: "--", GroupSize: 3},
+}
+
+func TestHexEvenLength(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 300; n++ {
+		input := make([]byte, r.Intn(100))
+		r.Read(input)
+		for _, h := range hexFormats {
+			out, err := h.Marshal(input)
+			require.Nil(t, err, "%+v", err)
+			checkHexInvariant(t, h, input, out)
+		}
+	}
+
+	// composed encoders keep it, counting the extra bytes they add
+	tagged := data.WithTag([]byte{0x01, 0x02, 0x03}, data.HexEncoder)
+	out, err := tagged.Marshal([]byte("abc"))
+	require.Nil(t, err, "%+v", err)
+	checkHexInvariant(t, data.HexFormat{}, []byte("\x01\x02\x03abc"), out)
+
+	// separators with hex digits would break it, so they are rejected
+	for _, sep := range []string{"a", "0", " F "} {
+		h := data.HexFormat{Separator: sep, GroupSize: 1}
+		_, err := h.Marshal([]byte{0x1a, 0x2b})
+		assert.NotNil(t, err, sep)
+		var back []byte
+		assert.NotNil(t, h.Unmarshal(&back, []byte(`"1a2b"`)), sep)
+	}
+}
+
+func FuzzHexEvenLength(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0x00})
+	f.Add([]byte{0x1a, 0x2b, 0x3c, 0x4d, 0x5e})
+	f.Add([]byte("\x00\xff\"\\quotes and escapes"))
+	f.Fuzz(func(t *testing.T, input []byte) {
+		for _, h := range hexFormats {
+			out, err := h.Marshal(input)
+			if err != nil {
+				t.Fatalf("%#v: %+v", h, err)
+			}
+			checkHexInvariant(t, h, input, out)
+		}
+	})
+}
+
+// invalidNearEnd returns a large encoding of random bytes from enc, with
+// the character at len-offset (ignoring the closing quote) replaced by bad
+func invalidNearEnd(t testing.TB, enc data.ByteEncoder, offset int, bad byte) []byte {
+	r := rand.New(rand.NewSource(3))
+	input := make([]byte, 1<<20)
+	r.Read(input)
+	out, err := enc.Marshal(input)
+	require.Nil(t, err, "%+v", err)
+	out[len(out)-1-offset] = bad
+	return out
+}
+
+var validateFirstCases = []struct {
+	name    string
+	encoder data.ByteEncoder
+	offset  int
+	bad     byte
+}{
+	{"hex", data.HexEncoder, 3, 'G'},
+	{"hex-space", data.HexEncoder, 1, ' '},
+	{"hex-grouped", data.HexFormat{Separator: " ", GroupSize: 4}, 3, 'x'},
+	{"base64", data.B64Encoder, 5, '!'},
+	{"base64-padding", data.B64Encoder, 8, '='},
+	{"rawbase64", data.RawB64Encoder, 2, '/'},
+	{"percent", data.PercentEncoder, 10, '%'},
+	{"base45", data.QRAlphanumEncoder, 4, 'a'},
+}
+
+func TestUnmarshalValidatesFirst(t *testing.T) {
+	for _, tc := range validateFirstCases {
+		src := invalidNearEnd(t, tc.encoder, tc.offset, tc.bad)
+
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		before := stats.TotalAlloc
+		var out []byte
+		err := tc.encoder.Unmarshal(&out, src)
+		runtime.ReadMemStats(&stats)
+
+		assert.NotNil(t, err, tc.name)
+		assert.Nil(t, out, tc.name)
+		// far less than the 1MB a decoded result would need
+		allocated := stats.TotalAlloc - before
+		assert.True(t, allocated < 64<<10, "%s: allocated %d bytes", tc.name, allocated)
+	}
+}
+
+func BenchmarkUnmarshalInvalid(b *testing.B) {
+	for _, tc := range validateFirstCases {
+		src := invalidNearEnd(b, tc.encoder, tc.offset, tc.bad)
+		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(src)))
+			for i := 0; i < b.N; i++ {
+				var out []byte
+				if tc.encoder.Unmarshal(&out, src) == nil {
+					b.Fatal("no error")
+				}
+			}
+		})
+	}
+}
+
+func TestStrictBase64Padding(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	defer func(strict bool) { data.StrictBase64Padding = strict }(data.StrictBase64Padding)
+	require.True(data.StrictBase64Padding)
+
+	star, err := data.NewCustomB64Encoder("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/", '*')
+	require.Nil(err)
+
+	cases := []struct {
+		encoder  data.ByteEncoder
+		input    string
+		expected data.Bytes
+		strict   bool
+		lenient  bool
+	}{
+		// correct padding always works
+		{data.B64Encoder, `"RCEuM3M="`, data.Bytes("D!.3s"), true, true},
+		{data.B64Encoder, `"RCEuMw=="`, data.Bytes("D!.3"), true, true},
+		{data.B64Encoder, `"RCEu"`, data.Bytes("D!."), true, true},
+		{data.B64Encoder, `""`, data.Bytes{}, true, true},
+		{star, `"RCEuMw**"`, data.Bytes("D!.3"), true, true},
+		// missing padding is repaired only if not strict
+		{data.B64Encoder, `"RCEuM3M"`, data.Bytes("D!.3s"), false, true},
+		{data.B64Encoder, `"RCEuMw"`, data.Bytes("D!.3"), false, true},
+		{data.B64Encoder, `"RCEuMw="`, data.Bytes("D!.3"), false, true},
+		{data.B64Encoder, "\"RCEu\\nMw\"", data.Bytes("D!.3"), false, true},
+		{star, `"RCEuMw*"`, data.Bytes("D!.3"), false, true},
+		{star, `"RCEuMw"`, data.Bytes("D!.3"), false, true},
+		// broken values stay broken
+		{data.B64Encoder, `"RCEuM"`, nil, false, false},
+		{data.B64Encoder, `"RCEuMw==="`, nil, false, false},
+		{data.B64Encoder, `"RC=uMw"`, nil, false, false},
+		{star, `"RCEuMw="`, nil, false, false},
+		// unpadded encoders never take padding
+		{data.RawB64Encoder, `"RCEuM3M"`, data.Bytes("D!.3s"), true, true},
+		{data.RawB64Encoder, `"RCEuM3M="`, nil, false, false},
+	}
+
+	for _, strict := range []bool{true, false} {
+		data.StrictBase64Padding = strict
+		for _, tc := range cases {
+			valid := tc.lenient
+			if strict {
+				valid = tc.strict
+			}
+			var res []byte
+			err := tc.encoder.Unmarshal(&res, []byte(tc.input))
+			if !valid {
+				assert.NotNil(err, "%s strict=%t", tc.input, strict)
+			} else if assert.Nil(err, "%s strict=%t: %+v", tc.input, strict, err) {
+				assert.Equal([]byte(tc.expected), res, tc.input)
+			}
+		}
+	}
+
+	// the input is not modified by the repair
+	data.StrictBase64Padding = false
+	src := []byte(`"RCEuMw"xx`)
+	var res []byte
+	require.Nil(data.B64Encoder.Unmarshal(&res, src[:8]))
+	assert.Equal(`"RCEuMw"xx`, string(src))
+
+	// nor copied, so pooled decoding still doesn't allocate
+	dec := data.NewPooledDecoder(data.B64Encoder)
+	src = []byte(`"RCEuM3NEIS4zcw"`)
+	allocs := testing.AllocsPerRun(100, func() {
+		p, err := dec.Decode(src)
+		if err == nil {
+			p.Release()
+		}
+	})
+	assert.Equal(0.0, allocs)
+}
@@ -0,0 +1,105 @@
+package data
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// CachingDecoder returns a ByteEncoder that remembers the results of the
+// last maxEntries distinct inputs to inner.Unmarshal, for hot loops that
+// decode the same values (like a set of addresses) over and over.
+//
+// Every Unmarshal returns a new copy, so callers may modify the result, and
+// the encoder is safe for concurrent use (if inner is). Errors are not
+// cached, and Marshal is passed on to inner unchanged.
+//
+// Values stay cached when settings that change how inner decodes do, like
+// StrictBase64Padding: an input decoded before is served as it was then.
+// Set those globals before decoding anything, or use a new CachingDecoder.
+//
+// It panics if maxEntries is not positive.
+func CachingDecoder(inner ByteEncoder, maxEntries int) ByteEncoder {
+	if maxEntries <= 0 {
+		panic(fmt.Sprintf("data: invalid cache size %d", maxEntries))
+	}
+	return &cachingEncoder{
+		inner:   inner,
+		max:     maxEntries,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, maxEntries),
+	}
+}
+
+// cachingEncoder implements ByteEncoder with an lru cache of decoded values.
+// order has the most recently used entry at the front.
+type cachingEncoder struct {
+	inner   ByteEncoder
+	max     int
+	mtx     sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key   string
+	value []byte
+}
+
+func (e *cachingEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (e *cachingEncoder) Unmarshal(dst *[]byte, src []byte) error {
+	if value, ok := e.get(src); ok {
+		*dst = copyBytes(value)
+		return nil
+	}
+	var res []byte
+	err := e.inner.Unmarshal(&res, src)
+	if err != nil {
+		return err
+	}
+	e.add(string(src), copyBytes(res))
+	*dst = res
+	return nil
+}
+
+func (e *cachingEncoder) Marshal(bytes []byte) ([]byte, error) {
+	return e.inner.Marshal(bytes)
+}
+
+func (e *cachingEncoder) get(src []byte) ([]byte, bool) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	el, ok := e.entries[string(src)]
+	if !ok {
+		return nil, false
+	}
+	e.order.MoveToFront(el)
+	return el.Value.(*cacheEntry).value, true
+}
+
+func (e *cachingEncoder) add(key string, value []byte) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	if el, ok := e.entries[key]; ok {
+		// added by someone else meanwhile
+		e.order.MoveToFront(el)
+		return
+	}
+	e.entries[key] = e.order.PushFront(&cacheEntry{key: key, value: value})
+	if e.order.Len() > e.max {
+		oldest := e.order.Back()
+		e.order.Remove(oldest)
+		delete(e.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// copyBytes returns a copy of b, keeping nil and empty apart
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append(make([]byte, 0, len(b)), b...)
+}
@@ -0,0 +1,126 @@
+package data_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingEncoder counts the calls to Unmarshal of the inner encoder
+type countingEncoder struct {
+	data.ByteEncoder
+	mtx   sync.Mutex
+	calls int
+}
+
+func (c *countingEncoder) Unmarshal(dst *[]byte, src []byte) error {
+	c.mtx.Lock()
+	c.calls++
+	c.mtx.Unlock()
+	return c.ByteEncoder.Unmarshal(dst, src)
+}
+
+func TestCachingDecoder(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	counter := &countingEncoder{ByteEncoder: data.HexEncoder}
+	cache := data.CachingDecoder(counter, 2)
+
+	decode := func(input string) []byte {
+		var out []byte
+		err := cache.Unmarshal(&out, []byte(input))
+		require.Nil(err, "%s: %+v", input, err)
+		return out
+	}
+
+	// hits return equal, but independent slices
+	first := decode(`"1A2B"`)
+	second := decode(`"1A2B"`)
+	assert.Equal(1, counter.calls)
+	assert.Equal([]byte{0x1a, 0x2b}, first)
+	assert.Equal(first, second)
+	first[0] = 0xff
+	second[1] = 0xff
+	assert.Equal([]byte{0x1a, 0x2b}, decode(`"1A2B"`))
+	assert.Equal(1, counter.calls)
+	assert.Equal([]byte{}, decode(`""`))
+	assert.Equal(2, counter.calls)
+	assert.Equal([]byte{}, decode(`""`))
+	assert.Equal(2, counter.calls)
+
+	// the least recently used is evicted: "1A2B" was used before ""
+	decode(`"3C4D"`)
+	assert.Equal(3, counter.calls)
+	decode(`""`)
+	decode(`"3C4D"`)
+	assert.Equal(3, counter.calls)
+	decode(`"1A2B"`)
+	assert.Equal(4, counter.calls)
+	// which evicted ""
+	decode(`"3C4D"`)
+	assert.Equal(4, counter.calls)
+	decode(`""`)
+	assert.Equal(5, counter.calls)
+
+	// errors are not cached
+	var out []byte
+	assert.NotNil(cache.Unmarshal(&out, []byte(`"1A2"`)))
+	assert.NotNil(cache.Unmarshal(&out, []byte(`"1A2"`)))
+	assert.Equal(7, counter.calls)
+
+	// marshal is unchanged
+	enc, err := cache.Marshal([]byte{0x1a, 0x2b})
+	require.Nil(err, "%+v", err)
+	assert.Equal(`"1A2B"`, string(enc))
+
+	assert.Panics(func() { data.CachingDecoder(data.HexEncoder, 0) })
+}
+
+func TestCachingDecoderConcurrent(t *testing.T) {
+	cache := data.CachingDecoder(data.HexEncoder, 10)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				v := byte((g + i) % 20)
+				var out []byte
+				err := cache.Unmarshal(&out, []byte(fmt.Sprintf(`"%02X"`, v)))
+				if assert.Nil(t, err, "%+v", err) {
+					assert.Equal(t, []byte{v}, out)
+					out[0]++
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+var benchHashes = func() [][]byte {
+	res := make([][]byte, 100)
+	for i := range res {
+		hash := data.Bytes(fmt.Sprintf("validator %d", i)).Hash(data.SHA256)
+		res[i], _ = data.HexEncoder.Marshal(hash)
+	}
+	return res
+}()
+
+func BenchmarkCachingDecoder(b *testing.B) {
+	run := func(b *testing.B, enc data.ByteEncoder) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var out []byte
+			if err := enc.Unmarshal(&out, benchHashes[i%len(benchHashes)]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.Run("uncached", func(b *testing.B) { run(b, data.HexEncoder) })
+	b.Run("cached", func(b *testing.B) { run(b, data.CachingDecoder(data.HexEncoder, 1000)) })
+}
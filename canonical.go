@@ -0,0 +1,151 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+)
+
+// canonicalEncoder is the pinned encoding of Bytes in CanonicalMarshal
+var canonicalEncoder ByteEncoder = HexFormat{Lower: true}
+
+// CanonicalMarshal returns the canonical json form of v, as defined by
+// RFC 8785 (JCS), for hashing or signing values in a way that other
+// languages can reproduce:
+//
+//   - object keys are sorted (by their utf-16 code units), at all levels
+//   - there is no whitespace between tokens
+//   - strings only escape what they must, eg. "<" stays as is
+//   - numbers are written in the shortest form that round trips through a
+//     float64, like javascript does. Integers above 2^53 lose precision!
+//
+// All Bytes in v are encoded as lowercase hex, no matter what the global
+// Encoder is, so the output does not depend on the program's settings.
+func CanonicalMarshal(v interface{}) ([]byte, error) {
+	js, err := MarshalJSONWith(v, canonicalEncoder)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	var parsed interface{}
+	err = dec.Decode(&parsed)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse json")
+	}
+	var buf bytes.Buffer
+	err = writeCanonical(&buf, parsed)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		if v {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return errors.Wrapf(err, "number %s", v)
+		}
+		// encoding/json formats float64 like ECMAScript, as JCS requires,
+		// except for negative zero, which ECMAScript prints as 0
+		if f == 0 {
+			f = 0
+		}
+		num, err := json.Marshal(f)
+		if err != nil {
+			return errors.Wrapf(err, "number %s", v)
+		}
+		buf.Write(num)
+	case string:
+		writeCanonicalString(buf, v)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, v[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return errors.Errorf("Unexpected json value: %T", v)
+	}
+	return nil
+}
+
+// writeCanonicalString quotes s, escaping only quotes, backslashes and
+// control characters
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// lessUTF16 compares strings by their utf-16 code units, which differs
+// from comparing the utf-8 bytes for characters outside the bmp
+func lessUTF16(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
@@ -0,0 +1,97 @@
+package data_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type CRecord struct {
+	Name  string            `json:"name"`
+	Key   data.Bytes        `json:"key"`
+	Attrs map[string]string `json:"attrs,omitempty"`
+	Score float64           `json:"score"`
+	Tags  []data.Bytes      `json:"tags"`
+}
+
+func TestCanonicalMarshal(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{nil, `null`},
+		{data.Bytes{0x1a, 0x2b}, `"1a2b"`},
+		{[]int{3, 1, 2}, `[3,1,2]`},
+		// keys are sorted, bytes are lowercase hex
+		{CRecord{Name: "x", Key: data.Bytes{0xab}, Score: 1.5, Tags: []data.Bytes{{0xcd}}},
+			`{"key":"ab","name":"x","score":1.5,"tags":["cd"]}`},
+		{map[string]interface{}{"b": 1, "a": map[string]int{"y": 2, "x": 1}},
+			`{"a":{"x":1,"y":2},"b":1}`},
+		// numbers as in ECMAScript
+		{[]float64{1, 1.0e21, 1e-7, 0.000001, -0, 123456789.25, 1e23}, `[1,1e+21,1e-7,0.000001,0,123456789.25,1e+23]`},
+		{map[string]int64{"big": 1<<53 + 1}, `{"big":9007199254740992}`},
+		{json.RawMessage(`{"a":-0,"b":[-0.0,-0e10]}`), `{"a":0,"b":[0,0]}`},
+		{math.Copysign(0, -1), `0`},
+		// minimal string escapes
+		{"<a & b> \"\\\n\x01\u00e9", `"<a & b> \"\\\n\u0001` + "\u00e9" + `"`},
+		{"line\u2028separator", `"line` + "\u2028" + `separator"`},
+		// keys sort by utf-16, so U+10000 (a surrogate pair) is before U+FFFD
+		{map[string]int{"\ufffd": 1, "\U00010000": 2, "a": 3}, `{"a":3,"` + "\U00010000" + `":2,"` + "\ufffd" + `":1}`},
+		// the example from RFC 8785, section 3.2.3
+		{map[string]interface{}{
+			"\u20ac":     "Euro Sign",
+			"\r":         "Carriage Return",
+			"\ufb33":     "Hebrew Letter Dalet With Dagesh",
+			"1":          "One",
+			"\U0001f600": "Emoji: Grinning Face",
+			"\u0080":     "Control",
+			"\u00f6":     "Latin Small Letter O With Diaeresis",
+		}, `{"\r":"Carriage Return","1":"One","` + "\u0080" + `":"Control","` + "\u00f6" +
+			`":"Latin Small Letter O With Diaeresis","` + "\u20ac" + `":"Euro Sign","` + "\U0001f600" +
+			`":"Emoji: Grinning Face","` + "\ufb33" + `":"Hebrew Letter Dalet With Dagesh"}`},
+	}
+
+	for i, tc := range cases {
+		out, err := data.CanonicalMarshal(tc.value)
+		require.Nil(err, "%d: %+v", i, err)
+		assert.Equal(tc.expected, string(out), "%d", i)
+	}
+}
+
+func TestCanonicalMarshalStable(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	// the same content, built in different orders
+	a := map[string]data.Bytes{}
+	b := map[string]data.Bytes{}
+	for i := 0; i < 50; i++ {
+		a[string(rune('A'+i))] = data.Bytes{byte(i)}
+	}
+	for i := 49; i >= 0; i-- {
+		b[string(rune('A'+i))] = data.Bytes{byte(i)}
+	}
+
+	expected, err := data.CanonicalMarshal(a)
+	require.Nil(err, "%+v", err)
+	for i := 0; i < 20; i++ {
+		out, err := data.CanonicalMarshal(a)
+		require.Nil(err, "%+v", err)
+		assert.Equal(expected, out)
+		out, err = data.CanonicalMarshal(b)
+		require.Nil(err, "%+v", err)
+		assert.Equal(expected, out)
+	}
+
+	// the global encoder makes no difference
+	defer data.SaveEncoder()()
+	data.Encoder = data.B64Encoder
+	out, err := data.CanonicalMarshal(a)
+	require.Nil(err, "%+v", err)
+	assert.Equal(expected, out)
+}
@@ -0,0 +1,67 @@
+package data
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+)
+
+// CappedBytes is a byte slice with a maximum length that is enforced when
+// it is unmarshaled, for fields that must not take arbitrary amounts of
+// memory. The limit is part of the value, so different fields can have
+// different ones:
+//
+//	msg := Msg{Memo: data.NewCappedBytes(256)}
+//	err := json.Unmarshal(input, &msg)
+//
+// It is marshaled with the global Encoder, like Bytes, and unmarshaled from
+// a json string with it (not from an array). If the Encoder implements
+// LenEncoder, text longer than the encoding of Max bytes is rejected before
+// decoding, so nothing beyond the cap is allocated. Other encoders decode
+// first, and check the length after.
+//
+// On any error the value is left untouched, and so it is for null, as
+// usual in encoding/json. The zero value has a cap of 0,
+// so it only accepts empty values.
+type CappedBytes struct {
+	Bytes Bytes
+	max   int
+}
+
+// NewCappedBytes returns an empty CappedBytes that accepts at most max
+// bytes
+func NewCappedBytes(max int) CappedBytes {
+	return CappedBytes{max: max}
+}
+
+// Max returns the maximum length
+func (c CappedBytes) Max() int {
+	return c.max
+}
+
+func (c CappedBytes) MarshalJSON() ([]byte, error) {
+	return c.Bytes.MarshalJSON()
+}
+
+func (c *CappedBytes) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.Equal(trimmed, []byte("null")) {
+		return nil
+	}
+	enc := Encoder
+	if le, ok := enc.(LenEncoder); ok {
+		if n := len(trimmed) - 2; n > le.EncodedLen(c.max) {
+			return errors.Errorf("Too long: %d characters encode more than %d bytes", n, c.max)
+		}
+	}
+	var res []byte
+	err := unmarshalHooked(enc, &res, data)
+	if err != nil {
+		return err
+	}
+	if len(res) > c.max {
+		return errors.WithStack(TooLongError{Len: len(res), Max: c.max})
+	}
+	c.Bytes = res
+	return nil
+}
@@ -0,0 +1,113 @@
+package data_test
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type CData struct {
+	Memo data.CappedBytes `json:"memo"`
+}
+
+func TestCappedBytes(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	defer data.SaveEncoder()()
+
+	cases := []struct {
+		encoder data.ByteEncoder
+		max     int
+		input   string
+		valid   bool
+	}{
+		// within the cap
+		{data.HexEncoder, 4, `"1A2B"`, true},
+		{data.HexEncoder, 4, `"1A2B3C4D"`, true},
+		{data.HexEncoder, 4, `""`, true},
+		{data.B64Encoder, 5, `"RCEuM3M="`, true},
+		{data.PercentEncoder, 3, `"a%20b"`, true},
+		{data.HexEncoder, 0, `""`, true},
+		// over it
+		{data.HexEncoder, 4, `"1A2B3C4D5E"`, false},
+		{data.HexEncoder, 0, `"1A"`, false},
+		{data.B64Encoder, 4, `"RCEuM3M="`, false},
+		{data.PercentEncoder, 2, `"a%20b"`, false},
+		// other errors
+		{data.HexEncoder, 4, `"1A2"`, false},
+		{data.HexEncoder, 4, `[26, 43]`, false},
+	}
+
+	for _, tc := range cases {
+		data.Encoder = tc.encoder
+		c := data.NewCappedBytes(tc.max)
+		assert.Equal(tc.max, c.Max())
+		c.Bytes = data.Bytes("before")
+
+		err := json.Unmarshal([]byte(tc.input), &c)
+		if !tc.valid {
+			assert.NotNil(err, tc.input)
+			// untouched on rejection
+			assert.Equal(data.Bytes("before"), c.Bytes, tc.input)
+			continue
+		}
+		require.Nil(err, "%s: %+v", tc.input, err)
+		assert.True(len(c.Bytes) <= tc.max)
+		assert.Equal(tc.max, c.Max())
+
+		// and back
+		out, err := json.Marshal(c)
+		require.Nil(err)
+		back := data.NewCappedBytes(tc.max)
+		require.Nil(json.Unmarshal(out, &back))
+		assert.Equal(c.Bytes, back.Bytes)
+	}
+
+	// the error says why, if the length is known after decoding
+	data.Encoder = data.PercentEncoder
+	c := data.NewCappedBytes(2)
+	var tooLong data.TooLongError
+	err := json.Unmarshal([]byte(`"abc"`), &c)
+	if assert.True(errors.As(err, &tooLong), "%+v", err) {
+		assert.Equal(data.TooLongError{Len: 3, Max: 2}, tooLong)
+	}
+
+	// per field, in a struct
+	data.Encoder = data.HexEncoder
+	msg := CData{Memo: data.NewCappedBytes(2)}
+	require.Nil(json.Unmarshal([]byte(`{"memo":"1A2B"}`), &msg))
+	assert.Equal(data.Bytes{0x1a, 0x2b}, msg.Memo.Bytes)
+	assert.NotNil(json.Unmarshal([]byte(`{"memo":"1A2B3C"}`), &msg))
+	assert.Equal(data.Bytes{0x1a, 0x2b}, msg.Memo.Bytes)
+	// null changes nothing
+	require.Nil(json.Unmarshal([]byte(`{"memo":null}`), &msg))
+	assert.Equal(data.Bytes{0x1a, 0x2b}, msg.Memo.Bytes)
+	out, err := json.Marshal(msg)
+	require.Nil(err)
+	assert.Equal(`{"memo":"1A2B"}`, string(out))
+}
+
+func TestCappedBytesAllocation(t *testing.T) {
+	defer data.SaveEncoder()()
+	data.Encoder = data.HexEncoder
+
+	// a valid 1MB value, for a field of 32 bytes
+	src := []byte(`"` + strings.Repeat("AB", 1<<20) + `"`)
+	c := data.NewCappedBytes(32)
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	before := stats.TotalAlloc
+	err := c.UnmarshalJSON(src)
+	runtime.ReadMemStats(&stats)
+
+	assert.NotNil(t, err)
+	allocated := stats.TotalAlloc - before
+	assert.True(t, allocated < 64<<10, "allocated %d bytes", allocated)
+}
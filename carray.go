@@ -0,0 +1,107 @@
+package data
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CArrayEncoder encodes bytes as a byte array literal for generated source
+// code: {0x1a, 0x2b} for Lang "c", or []byte{0x1a, 0x2b} for Lang "go".
+//
+// Unmarshal parses such literals back, allowing any whitespace, a trailing
+// comma, and decimal or hex (0x) numbers up to 255. Any other Lang is an
+// error.
+type CArrayEncoder struct {
+	Lang string
+}
+
+func (e CArrayEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+// prefix returns what comes before the braces of the literal
+func (e CArrayEncoder) prefix() (string, error) {
+	switch e.Lang {
+	case "c":
+		return "", nil
+	case "go":
+		return "[]byte", nil
+	}
+	return "", errors.Errorf("Unknown language: %q", e.Lang)
+}
+
+func (e CArrayEncoder) Unmarshal(dst *[]byte, src []byte) (err error) {
+	prefix, err := e.prefix()
+	if err != nil {
+		return err
+	}
+	s, err := ParseJSONString(src)
+	if err != nil {
+		return err
+	}
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, prefix) {
+		return errors.Errorf("Missing %q before array literal", prefix)
+	}
+	s = strings.TrimSpace(s[len(prefix):])
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return errors.New("Array literal must be enclosed in braces")
+	}
+
+	items := strings.Split(s[1:len(s)-1], ",")
+	// allow a trailing comma, and {}
+	if strings.TrimSpace(items[len(items)-1]) == "" {
+		items = items[:len(items)-1]
+	}
+	res := make([]byte, 0, len(items))
+	for i, item := range items {
+		c, ok := parseByteLiteral(strings.TrimSpace(item))
+		if !ok {
+			return errors.Errorf("Invalid byte at index %d: %q", i, item)
+		}
+		res = append(res, c)
+	}
+	*dst = res
+	return nil
+}
+
+func (e CArrayEncoder) Marshal(bytes []byte) ([]byte, error) {
+	prefix, err := e.prefix()
+	if err != nil {
+		return nil, err
+	}
+	var s strings.Builder
+	s.WriteString(prefix)
+	s.WriteByte('{')
+	for i, c := range bytes {
+		if i > 0 {
+			s.WriteString(", ")
+		}
+		s.WriteString("0x")
+		s.WriteByte(lowerHex[c>>4])
+		s.WriteByte(lowerHex[c&0xf])
+	}
+	s.WriteByte('}')
+	return json.Marshal(s.String())
+}
+
+const lowerHex = "0123456789abcdef"
+
+// parseByteLiteral parses a decimal or 0x prefixed hex number up to 255.
+// Octal and other forms that only some languages know are rejected.
+func parseByteLiteral(s string) (byte, bool) {
+	base := 10
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		s, base = s[2:], 16
+	} else if len(s) > 1 && s[0] == '0' {
+		return 0, false
+	}
+	if s == "" || strings.ContainsAny(s, "_+-") {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(s, base, 8)
+	return byte(v), err == nil
+}
@@ -0,0 +1,93 @@
+package data_test
+
+import (
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCArrayEncoder(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	c := data.CArrayEncoder{Lang: "c"}
+	golang := data.CArrayEncoder{Lang: "go"}
+	cases := []struct {
+		encoder  data.ByteEncoder
+		input    []byte
+		expected string
+	}{
+		{c, []byte{0x1a, 0x2b}, `"{0x1a, 0x2b}"`},
+		{c, []byte{0x00, 0xff}, `"{0x00, 0xff}"`},
+		{c, []byte{}, `"{}"`},
+		{golang, []byte{0x1a, 0x2b}, `"[]byte{0x1a, 0x2b}"`},
+		{golang, []byte{0x07}, `"[]byte{0x07}"`},
+		{golang, []byte{}, `"[]byte{}"`},
+	}
+
+	for _, tc := range cases {
+		out, err := tc.encoder.Marshal(tc.input)
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.expected, string(out))
+
+		var back []byte
+		err = tc.encoder.Unmarshal(&back, out)
+		require.Nil(err, "%s: %+v", tc.expected, err)
+		assert.Equal(tc.input, back, tc.expected)
+	}
+}
+
+func TestCArrayEncoderUnmarshal(t *testing.T) {
+	assert := assert.New(t)
+
+	c := data.CArrayEncoder{Lang: "c"}
+	golang := data.CArrayEncoder{Lang: "go"}
+	cases := []struct {
+		encoder  data.ByteEncoder
+		input    string
+		expected []byte
+	}{
+		// formatting as written by hand or gofmt
+		{c, `"{0x1A,0x2b}"`, []byte{0x1a, 0x2b}},
+		{c, `" { 26, 43, 0 } "`, []byte{0x1a, 0x2b, 0x00}},
+		{golang, `"[]byte{\n\t0x1a,\n\t0x2b,\n}"`, []byte{0x1a, 0x2b}},
+		{golang, `"[]byte {0x1a}"`, []byte{0x1a}},
+		{c, `"{ }"`, []byte{}},
+		{c, `"{0x1a,}"`, []byte{0x1a}},
+		{c, `"{0, 255}"`, []byte{0x00, 0xff}},
+		// malformed
+		{c, `"[]byte{0x1a}"`, nil},
+		{golang, `"{0x1a}"`, nil},
+		{c, `"{0x1a, 0x2b"`, nil},
+		{c, `"0x1a, 0x2b}"`, nil},
+		{c, `"{0x1a,, 0x2b}"`, nil},
+		{c, `"{,}"`, nil},
+		{c, `"{0x100}"`, nil},
+		{c, `"{-1}"`, nil},
+		{c, `"{0x1g}"`, nil},
+		{c, `"{'a'}"`, nil},
+		{c, `"{017}"`, nil},
+		{c, `"{0b101}"`, nil},
+		{c, `"{1_0}"`, nil},
+		{c, `"{0x}"`, nil},
+		{c, `"{+1}"`, nil},
+		{c, `"{0x1a 0x2b}"`, nil},
+		// unknown language
+		{data.CArrayEncoder{Lang: "rust"}, `"{0x1a}"`, nil},
+		{data.CArrayEncoder{}, `"{0x1a}"`, nil},
+	}
+
+	for _, tc := range cases {
+		var out []byte
+		err := tc.encoder.Unmarshal(&out, []byte(tc.input))
+		if tc.expected == nil {
+			assert.NotNil(err, tc.input)
+		} else if assert.Nil(err, "%s: %+v", tc.input, err) {
+			assert.Equal(tc.expected, out, tc.input)
+		}
+	}
+
+	_, err := data.CArrayEncoder{Lang: "rust"}.Marshal([]byte{0x1a})
+	assert.NotNil(err)
+}
@@ -0,0 +1,74 @@
+package data
+
+import (
+	"crypto/subtle"
+	"math/bits"
+
+	"github.com/pkg/errors"
+)
+
+// EqualEncoded returns true if s is the encoding of b under enc.
+//
+// It encodes b and compares the text to s (without json quotes), so there
+// is no need to decode s. For HexFormat encoders the comparison ignores
+// case, as the decoder accepts either, unless StrictCase is set. All other
+// encoders compare exactly, which is right for base64 and base58, where
+// case matters, but means that only the form produced by Marshal matches
+// (eg. padded for B64Encoder).
+func (b Bytes) EqualEncoded(s string, enc ByteEncoder) bool {
+	d, err := enc.Marshal(b)
+	if err != nil {
+		return false
+	}
+	encoded, err := ParseJSONString(d)
+	if err != nil {
+		return false
+	}
+	if h, ok := enc.(HexFormat); ok {
+		return h.equalCase(encoded, s)
+	}
+	return encoded == s
+}
+
+// EqualHexLiteral returns true if s is the hex encoding of b, in either
+// case, without allocating. It is meant for comparing against constants in
+// hot paths. s must be plain hex digits, an odd length or any other
+// character never matches.
+func (b Bytes) EqualHexLiteral(s string) bool {
+	if len(s) != 2*len(b) {
+		return false
+	}
+	for i, c := range b {
+		hi, ok1 := unhex(s[2*i])
+		lo, ok2 := unhex(s[2*i+1])
+		if !ok1 || !ok2 || hi<<4|lo != c {
+			return false
+		}
+	}
+	return true
+}
+
+// HasPrefixConstantTime returns true if b starts with p, taking time that
+// only depends on len(p), not on the content of either.
+//
+// If b is shorter than p it returns false right away, so only the lengths
+// may leak through timing.
+func (b Bytes) HasPrefixConstantTime(p Bytes) bool {
+	if len(b) < len(p) {
+		return false
+	}
+	return subtle.ConstantTimeCompare(b[:len(p)], p) == 1
+}
+
+// HammingDistance returns the number of bits that differ between b and
+// other, eg. to compare perceptual hashes. They must have the same length.
+func (b Bytes) HammingDistance(other Bytes) (int, error) {
+	if len(b) != len(other) {
+		return 0, errors.Errorf("Length mismatch: %d != %d", len(b), len(other))
+	}
+	dist := 0
+	for i := range b {
+		dist += bits.OnesCount8(b[i] ^ other[i])
+	}
+	return dist, nil
+}
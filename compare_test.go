@@ -0,0 +1,159 @@
+package data_test
+
+import (
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEqualEncoded(t *testing.T) {
+	assert := assert.New(t)
+
+	b := data.Bytes{0x0f, 0x8f, 0xda, 0xfb, 0xed}
+	cases := []struct {
+		encoder  data.ByteEncoder
+		encoded  string
+		expected bool
+	}{
+		// hex ignores case
+		{data.HexEncoder, "0F8FDAFBED", true},
+		{data.HexEncoder, "0f8fdafbed", true},
+		{data.HexEncoder, "0f8FdaFBed", true},
+		{data.HexEncoder, "0F8FDAFBEE", false},
+		{data.HexEncoder, "0F8FDAFB", false},
+		{data.HexEncoder, `"0F8FDAFBED"`, false},
+		{data.HexFormat{Prefix: "0x"}, "0X0f8fdafbed", true},
+		// unless the decoder is strict
+		{data.HexFormat{Lower: true, StrictCase: true}, "0f8fdafbed", true},
+		{data.HexFormat{Lower: true, StrictCase: true}, "0F8FDAFBED", false},
+		{data.HexFormat{StrictCase: true}, "0f8FdaFBed", false},
+		// base64 is exact
+		{data.B64Encoder, "D4_a--0=", true},
+		{data.B64Encoder, "d4_a--0=", false},
+		{data.B64Encoder, "D4_a--0", false},
+		{data.RawB64Encoder, "D4_a--0", true},
+		{data.RawB64Encoder, "D4/a++0", false},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(tc.expected, b.EqualEncoded(tc.encoded, tc.encoder), tc.encoded)
+	}
+
+	assert.True(data.Bytes{}.EqualEncoded("", data.HexEncoder))
+	assert.True(data.Bytes(nil).EqualEncoded("", data.B64Encoder))
+}
+
+func TestHasPrefixConstantTime(t *testing.T) {
+	assert := assert.New(t)
+
+	token := data.Bytes{0xde, 0xad, 0xbe, 0xef}
+	cases := []struct {
+		bytes, prefix data.Bytes
+		expected      bool
+	}{
+		{token, data.Bytes{0xde, 0xad}, true},
+		{token, token, true},
+		{token, data.Bytes{}, true},
+		{nil, nil, true},
+		// non-matching
+		{token, data.Bytes{0xde, 0xae}, false},
+		{token, data.Bytes{0xad}, false},
+		// receiver too short
+		{token, data.Bytes{0xde, 0xad, 0xbe, 0xef, 0x00}, false},
+		{nil, data.Bytes{0x00}, false},
+	}
+
+	for i, tc := range cases {
+		assert.Equal(tc.expected, tc.bytes.HasPrefixConstantTime(tc.prefix), "%d", i)
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		a, b     data.Bytes
+		expected int
+	}{
+		{nil, nil, 0},
+		{data.Bytes{}, nil, 0},
+		// identical
+		{data.Bytes{0x1a, 0x2b}, data.Bytes{0x1a, 0x2b}, 0},
+		// fully different
+		{data.Bytes{0x00}, data.Bytes{0xff}, 8},
+		{data.Bytes{0x55, 0x0f}, data.Bytes{0xaa, 0xf0}, 16},
+		// partly
+		{data.Bytes{0x01, 0x00}, data.Bytes{0x00, 0x00}, 1},
+		{data.Bytes{0x1a, 0x2b, 0x3c}, data.Bytes{0x1b, 0x2b, 0xc3}, 9},
+	}
+
+	for i, tc := range cases {
+		dist, err := tc.a.HammingDistance(tc.b)
+		if assert.Nil(err, "%d: %+v", i, err) {
+			assert.Equal(tc.expected, dist, "%d", i)
+		}
+		// symmetric
+		dist, err = tc.b.HammingDistance(tc.a)
+		if assert.Nil(err, "%d: %+v", i, err) {
+			assert.Equal(tc.expected, dist, "%d", i)
+		}
+	}
+
+	// length mismatch
+	_, err := data.Bytes{0x1a, 0x2b}.HammingDistance(data.Bytes{0x1a})
+	assert.NotNil(err)
+	_, err = data.Bytes{}.HammingDistance(data.Bytes{0x00})
+	assert.NotNil(err)
+}
+
+func TestEqualHexLiteral(t *testing.T) {
+	assert := assert.New(t)
+
+	b := data.Bytes{0x1a, 0x2b, 0xcd}
+	cases := []struct {
+		bytes    data.Bytes
+		literal  string
+		expected bool
+	}{
+		{b, "1A2BCD", true},
+		{b, "1a2bcd", true},
+		{b, "1a2BcD", true},
+		{data.Bytes{}, "", true},
+		{nil, "", true},
+		// not matching
+		{b, "1A2BCE", false},
+		{b, "2A2BCD", false},
+		{b, "1A2B00", false},
+		// wrong length
+		{b, "1A2B", false},
+		{b, "1A2BCD00", false},
+		{nil, "00", false},
+		// odd length, or not hex
+		{b, "1A2BC", false},
+		{b, "1A2BCDE", false},
+		{b, "0x1A2BCD", false},
+		{b, "1A2BCG", false},
+		{b, "1A-2BCD", false},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(tc.expected, tc.bytes.EqualHexLiteral(tc.literal), tc.literal)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		b.EqualHexLiteral("1A2BCD")
+	})
+	assert.Equal(0.0, allocs)
+}
+
+func BenchmarkEqualHexLiteral(b *testing.B) {
+	hash := data.Bytes("some 32 byte hash for comparison")
+	literal := "736F6D652033322062797465206861736820666F7220636F6D70617269736F6E"
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if !hash.EqualHexLiteral(literal) {
+			b.Fatal("no match")
+		}
+	}
+}
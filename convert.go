@@ -0,0 +1,88 @@
+package data
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// IP interprets the bytes as an IPv4 (4 bytes) or IPv6 (16 bytes) address.
+// Any other length is an error.
+func (b Bytes) IP() (net.IP, error) {
+	if len(b) != net.IPv4len && len(b) != net.IPv6len {
+		return nil, errors.Errorf("Invalid IP length: %d", len(b))
+	}
+	return append(net.IP{}, b...), nil
+}
+
+// BytesFromIP returns the raw bytes of ip: 4 bytes for IPv4 addresses (also
+// when stored in the 16 byte form), 16 bytes for IPv6. It returns nil if ip
+// is not a valid address.
+func BytesFromIP(ip net.IP) Bytes {
+	if v4 := ip.To4(); v4 != nil {
+		return append(Bytes{}, v4...)
+	}
+	if len(ip) != net.IPv6len {
+		return nil
+	}
+	return append(Bytes{}, ip...)
+}
+
+// Time interprets the bytes as a big-endian int64 of nanoseconds since the
+// unix epoch, as written by BytesFromTime. It must be exactly 8 bytes.
+func (b Bytes) Time() (time.Time, error) {
+	if len(b) != 8 {
+		return time.Time{}, errors.Errorf("Invalid time length: %d", len(b))
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(b))), nil
+}
+
+// BytesFromTime encodes t as 8 bytes of big-endian unix nanoseconds.
+// This only covers the years 1678 to 2262, see time.Time.UnixNano.
+func BytesFromTime(t time.Time) Bytes {
+	res := make(Bytes, 8)
+	binary.BigEndian.PutUint64(res, uint64(t.UnixNano()))
+	return res
+}
+
+// UUID formats 16 bytes in the canonical 8-4-4-4-12 form with lowercase
+// hex, like "f81d4fae-7dec-11d0-a765-00a0c91e6bf6". Any other length is an
+// error. The version and variant bits are not checked.
+func (b Bytes) UUID() (string, error) {
+	if len(b) != 16 {
+		return "", errors.Errorf("Invalid UUID length: %d", len(b))
+	}
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], b[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], b[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], b[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], b[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], b[10:])
+	return string(buf), nil
+}
+
+// BytesFromUUID parses a UUID in the canonical 8-4-4-4-12 form (in either
+// case) into its 16 bytes. Other forms, like with braces or a "urn:uuid:"
+// prefix, are rejected.
+func BytesFromUUID(s string) (Bytes, error) {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return nil, errors.Errorf("Invalid UUID: %q", s)
+	}
+	digits := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	res, err := hex.DecodeString(digits)
+	if err != nil {
+		err = errors.Errorf("Invalid UUID: %q", s)
+	}
+	err = formatHook("uuid", len(s), res, err)
+	if err != nil {
+		return nil, err
+	}
+	return Bytes(res), nil
+}
@@ -0,0 +1,133 @@
+package data_test
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIP(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cases := []struct {
+		ip    string
+		bytes data.Bytes
+	}{
+		{"192.168.1.20", data.Bytes{192, 168, 1, 20}},
+		{"0.0.0.0", data.Bytes{0, 0, 0, 0}},
+		{"2001:db8::1", data.Bytes{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x01}},
+		{"::1", data.Bytes{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x01}},
+	}
+
+	for _, tc := range cases {
+		ip := net.ParseIP(tc.ip)
+		require.NotNil(ip, tc.ip)
+		b := data.BytesFromIP(ip)
+		assert.Equal(tc.bytes, b, tc.ip)
+
+		parsed, err := tc.bytes.IP()
+		require.Nil(err, "%s: %+v", tc.ip, err)
+		assert.True(ip.Equal(parsed), tc.ip)
+		assert.Equal(tc.ip, parsed.String())
+	}
+
+	// invalid lengths
+	for _, b := range []data.Bytes{nil, {}, {1, 2, 3}, {1, 2, 3, 4, 5}, make(data.Bytes, 15)} {
+		_, err := b.IP()
+		assert.NotNil(err, "%X", b)
+	}
+	assert.Nil(data.BytesFromIP(nil))
+	assert.Nil(data.BytesFromIP(net.IP{1, 2, 3}))
+}
+
+func TestTime(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cases := []struct {
+		time  time.Time
+		bytes data.Bytes
+	}{
+		{time.Unix(0, 0), data.Bytes{0, 0, 0, 0, 0, 0, 0, 0}},
+		{time.Unix(0, 1), data.Bytes{0, 0, 0, 0, 0, 0, 0, 1}},
+		{time.Unix(1500000000, 123), data.Bytes{0x14, 0xd1, 0x12, 0x0d, 0x7b, 0x16, 0x00, 0x7b}},
+		// before the epoch
+		{time.Unix(0, -1), data.Bytes{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	}
+
+	for _, tc := range cases {
+		b := data.BytesFromTime(tc.time)
+		assert.Equal(tc.bytes, b, "%s", tc.time)
+		res, err := b.Time()
+		require.Nil(err, "%+v", err)
+		assert.True(tc.time.Equal(res), "%s != %s", tc.time, res)
+	}
+
+	// round trip keeps the instant, whatever the location
+	now := time.Now().In(time.FixedZone("test", 3600))
+	res, err := data.BytesFromTime(now).Time()
+	require.Nil(err, "%+v", err)
+	assert.True(now.Equal(res))
+
+	// wrong length
+	for _, b := range []data.Bytes{nil, {}, {1, 2, 3, 4, 5, 6, 7}, {1, 2, 3, 4, 5, 6, 7, 8, 9}} {
+		_, err := b.Time()
+		assert.NotNil(err, "%X", []byte(b))
+	}
+}
+
+func TestUUID(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cases := []struct {
+		uuid  string
+		bytes data.Bytes
+	}{
+		// from RFC 4122
+		{"f81d4fae-7dec-11d0-a765-00a0c91e6bf6", data.Bytes{0xf8, 0x1d, 0x4f, 0xae, 0x7d, 0xec, 0x11, 0xd0,
+			0xa7, 0x65, 0x00, 0xa0, 0xc9, 0x1e, 0x6b, 0xf6}},
+		{"00000000-0000-0000-0000-000000000000", make(data.Bytes, 16)},
+		{"ffffffff-ffff-ffff-ffff-ffffffffffff", data.Bytes(bytes.Repeat([]byte{0xff}, 16))},
+	}
+
+	for _, tc := range cases {
+		s, err := tc.bytes.UUID()
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.uuid, s)
+		b, err := data.BytesFromUUID(tc.uuid)
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.bytes, b)
+		// uppercase is accepted too
+		b, err = data.BytesFromUUID(strings.ToUpper(tc.uuid))
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.bytes, b)
+	}
+
+	// wrong length
+	for _, b := range []data.Bytes{nil, {}, make(data.Bytes, 15), make(data.Bytes, 17)} {
+		_, err := b.UUID()
+		assert.NotNil(err, "%d", len(b))
+	}
+
+	// malformed
+	bad := []string{
+		"",
+		"f81d4fae7dec11d0a76500a0c91e6bf6",
+		"{f81d4fae-7dec-11d0-a765-00a0c91e6bf6}",
+		"urn:uuid:f81d4fae-7dec-11d0-a765-00a0c91e6bf6",
+		"f81d4fae-7dec-11d0-a765-00a0c91e6bf",
+		"f81d4fae-7dec-11d0-a765-00a0c91e6bf6a",
+		"f81d4fa-e7dec-11d0-a765-00a0c91e6bf6",
+		"f81d4fae-7dec-11d0-a765-00a0c91e6bfg",
+		"f81d4fae_7dec_11d0_a765_00a0c91e6bf6",
+	}
+	for _, s := range bad {
+		_, err := data.BytesFromUUID(s)
+		assert.NotNil(err, s)
+	}
+}
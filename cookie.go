@@ -0,0 +1,45 @@
+package data
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// CookieEncoder encodes bytes so they can be used as a cookie value as is.
+//
+// It produces url-safe base64 without padding, whose alphabet (letters,
+// digits, '-' and '_') only has characters that RFC 6265 allows and that
+// no cookie parser treats specially, so no further escaping is needed.
+//
+// Since cookies often pass through code that escapes or pads values, it
+// also accepts percent-escaped input and optional padding on Unmarshal,
+// eg. "Zm8%3D" for "Zm8".
+var CookieEncoder ByteEncoder = cookieEncoder{}
+
+// cookieEncoder implements ByteEncoder
+type cookieEncoder struct{}
+
+func (e cookieEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (_ cookieEncoder) Unmarshal(dst *[]byte, src []byte) (err error) {
+	s, err := ParseJSONString(src)
+	if err != nil {
+		return err
+	}
+	s, err = url.PathUnescape(s)
+	if err != nil {
+		return errors.Wrap(err, "unescape cookie value")
+	}
+	*dst, err = decodeURLSafe(s)
+	return err
+}
+
+func (_ cookieEncoder) Marshal(bytes []byte) ([]byte, error) {
+	s := base64.RawURLEncoding.EncodeToString(bytes)
+	return json.Marshal(s)
+}
@@ -0,0 +1,81 @@
+package data_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCookieEncoder(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cases := []struct {
+		input    []byte
+		expected string
+	}{
+		{[]byte{}, ""},
+		{[]byte("fo"), "Zm8"},
+		// these need '+', '/' and '=' in std base64
+		{[]byte{0xfb, 0xff, 0xbf}, "-_-_"},
+		{[]byte{0xfb, 0xef}, "--8"},
+		{[]byte("session=1; path=/"), "c2Vzc2lvbj0xOyBwYXRoPS8"},
+	}
+
+	for _, tc := range cases {
+		out, err := data.CookieEncoder.Marshal(tc.input)
+		require.Nil(err, "%+v", err)
+		assert.Equal(`"`+tc.expected+`"`, string(out))
+
+		var back []byte
+		err = data.CookieEncoder.Unmarshal(&back, out)
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.input, back)
+
+		// net/http takes the value unchanged, and parses it back
+		value := strings.Trim(string(out), `"`)
+		cookie := &http.Cookie{Name: "id", Value: value}
+		assert.Equal("id="+value, cookie.String())
+		req := &http.Request{Header: http.Header{"Cookie": {cookie.String()}}}
+		parsed, err := req.Cookie("id")
+		require.Nil(err, "%+v", err)
+		assert.Equal(value, parsed.Value)
+	}
+}
+
+func TestCookieEncoderUnmarshal(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		input    string
+		expected []byte
+	}{
+		{`"Zm8"`, []byte("fo")},
+		// padded or escaped
+		{`"Zm8="`, []byte("fo")},
+		{`"Zm8%3D"`, []byte("fo")},
+		{`"Zm8%3d"`, []byte("fo")},
+		{`"%2D%5F-_"`, []byte{0xfb, 0xff, 0xbf}},
+		// errors
+		{`"Zm8%3"`, nil},
+		{`"+/+/"`, nil},
+		{`"Z"`, nil},
+		{`"Zm8;"`, nil},
+		{`"Zm8====="`, nil},
+		{`"Zm9v="`, nil},
+		{`12`, nil},
+	}
+
+	for _, tc := range cases {
+		var out []byte
+		err := data.CookieEncoder.Unmarshal(&out, []byte(tc.input))
+		if tc.expected == nil {
+			assert.NotNil(err, tc.input)
+		} else if assert.Nil(err, "%s: %+v", tc.input, err) {
+			assert.Equal(tc.expected, out, tc.input)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+/*
+Package datatest has helpers for tests of code using data.
+
+It is separate so the main package doesn't depend on testing.
+*/
+package datatest
+
+import (
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+)
+
+// WithEncoder sets data.Encoder to enc for the rest of the test, and
+// registers a cleanup to restore the previous Encoder after it (and its
+// subtests) finish, so one test cannot change the encoding of the next.
+//
+// As data.Encoder is global, tests using it must not run in parallel.
+func WithEncoder(t testing.TB, enc data.ByteEncoder) {
+	t.Helper()
+	t.Cleanup(data.SaveEncoder())
+	data.Encoder = enc
+}
@@ -0,0 +1,45 @@
+package datatest_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/neatio-net/data-go/datatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEncoder(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	data.Encoder = data.HexEncoder
+	t.Run("base64", func(t *testing.T) {
+		datatest.WithEncoder(t, data.B64Encoder)
+		assert.Equal(data.B64Encoder, data.Encoder)
+		d, err := json.Marshal(data.Bytes("foo"))
+		require.Nil(err, "%+v", err)
+		assert.Equal(`"Zm9v"`, string(d))
+
+		// nested changes unwind in order
+		t.Run("raw", func(t *testing.T) {
+			datatest.WithEncoder(t, data.RawB64Encoder)
+			assert.Equal(data.RawB64Encoder, data.Encoder)
+		})
+		assert.Equal(data.B64Encoder, data.Encoder)
+	})
+	// restored after the cleanup
+	assert.Equal(data.HexEncoder, data.Encoder)
+}
+
+func TestSaveEncoder(t *testing.T) {
+	assert := assert.New(t)
+
+	data.Encoder = data.HexEncoder
+	func() {
+		defer data.SaveEncoder()()
+		data.Encoder = data.B64Encoder
+		assert.Equal(data.B64Encoder, data.Encoder)
+	}()
+	assert.Equal(data.HexEncoder, data.Encoder)
+}
@@ -0,0 +1,66 @@
+package data
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// DecimalBytesEncoder encodes bytes as the decimal string of the big-endian
+// number they hold, like "6699" for {0x1a, 0x2b}, for systems that only
+// deal in decimal strings.
+//
+// Unmarshal returns the minimal big-endian bytes of the number, so leading
+// zero bytes don't survive a round trip, and "0" is empty bytes (as are
+// "" and null). Only the digits 0-9 are allowed, no sign or spaces.
+//
+// The conversion is quadratic in the length, so values are limited to 1024
+// bytes: Marshal rejects longer input, and Unmarshal rejects more than
+// 2467 digits (enough for any 1024 byte value) before converting.
+var DecimalBytesEncoder ByteEncoder = decimalEncoder{}
+
+const (
+	// maxDecimalBytes is the longest value DecimalBytesEncoder converts
+	maxDecimalBytes = 1024
+	// maxDecimalDigits is the number of digits of 256^maxDecimalBytes - 1
+	maxDecimalDigits = 2467
+)
+
+// decimalEncoder implements ByteEncoder with math/big
+type decimalEncoder struct{}
+
+func (e decimalEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (_ decimalEncoder) Unmarshal(dst *[]byte, src []byte) error {
+	s, err := ParseJSONString(src)
+	if err != nil {
+		return err
+	}
+	if len(s) > maxDecimalDigits {
+		return errors.Errorf("Decimal value exceeds %d digits", maxDecimalDigits)
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return errors.Errorf("Encountered non-decimal character: %q", s[i])
+		}
+	}
+	num := new(big.Int)
+	if s != "" {
+		num.SetString(s, 10)
+	}
+	if num.BitLen() > 8*maxDecimalBytes {
+		return errors.Errorf("Decimal value exceeds %d bytes", maxDecimalBytes)
+	}
+	*dst = num.Bytes()
+	return nil
+}
+
+func (_ decimalEncoder) Marshal(bytes []byte) ([]byte, error) {
+	if len(bytes) > maxDecimalBytes {
+		return nil, errors.Errorf("Value exceeds %d bytes for decimal", maxDecimalBytes)
+	}
+	return json.Marshal(new(big.Int).SetBytes(bytes).String())
+}
@@ -0,0 +1,91 @@
+package data_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecimalBytesEncoder(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	enc := data.DecimalBytesEncoder
+	max256 := data.Bytes{
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	}
+	cases := []struct {
+		input    data.Bytes
+		expected string
+	}{
+		// zero
+		{data.Bytes{}, `"0"`},
+		// small
+		{data.Bytes{0x01}, `"1"`},
+		{data.Bytes{0xff}, `"255"`},
+		{data.Bytes{0x1a, 0x2b}, `"6699"`},
+		{data.Bytes{0x01, 0x00, 0x00}, `"65536"`},
+		// large
+		{max256, `"115792089237316195423570985008687907853269984665640564039457584007913129639935"`},
+	}
+
+	for _, tc := range cases {
+		out, err := enc.Marshal(tc.input)
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.expected, string(out))
+
+		var back []byte
+		err = enc.Unmarshal(&back, out)
+		if assert.Nil(err, "%s: %+v", out, err) {
+			assert.Equal([]byte(tc.input), back)
+		}
+	}
+
+	// leading zeros are dropped, both ways
+	inputs := []struct {
+		input    string
+		expected data.Bytes
+	}{
+		{`"0"`, data.Bytes{}},
+		{`"000"`, data.Bytes{}},
+		{`""`, data.Bytes{}},
+		{`null`, data.Bytes{}},
+		{`"006699"`, data.Bytes{0x1a, 0x2b}},
+	}
+	for _, tc := range inputs {
+		var back []byte
+		err := enc.Unmarshal(&back, []byte(tc.input))
+		if assert.Nil(err, "%s: %+v", tc.input, err) {
+			assert.Equal([]byte(tc.expected), back, tc.input)
+		}
+	}
+	out, err := enc.Marshal([]byte{0x00, 0x00, 0x1a, 0x2b})
+	require.Nil(err)
+	assert.Equal(`"6699"`, string(out))
+
+	// only plain digits
+	for _, bad := range []string{`"-1"`, `"+1"`, `"1a2b"`, `"0x1a"`, `" 12"`, `"1_000"`, `"1.5"`, `"1e3"`, `12`} {
+		var back []byte
+		assert.NotNil(enc.Unmarshal(&back, []byte(bad)), bad)
+	}
+
+	// the limit is 1024 bytes, or as many digits as that takes
+	max := bytes.Repeat([]byte{0xff}, 1024)
+	out, err = enc.Marshal(max)
+	require.Nil(err, "%+v", err)
+	assert.Equal(2467+2, len(out))
+	var back []byte
+	require.Nil(enc.Unmarshal(&back, out))
+	assert.Equal(max, back)
+	_, err = enc.Marshal(append(max, 0xff))
+	assert.NotNil(err)
+	for _, bad := range []string{strings.Repeat("9", 2467), strings.Repeat("0", 2468), strings.Repeat("1", 100000)} {
+		assert.NotNil(enc.Unmarshal(&back, []byte(`"`+bad+`"`)), "%d digits", len(bad))
+	}
+}
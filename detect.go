@@ -0,0 +1,84 @@
+package data
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// detectHint describes how likely a registered encoding is to be used,
+// and the number of distinct characters in its output
+type detectHint struct {
+	prior    float64
+	alphabet float64
+}
+
+// detectHints has the known encodings, all others get defaultDetectHint.
+// Percent encoding gets a low prior, as it accepts most plain words.
+var detectHints = map[string]detectHint{
+	"hex":          {1, 16},
+	"base64":       {1, 64},
+	"rawbase64":    {1, 64},
+	"base58":       {1, 58},
+	"base58flickr": {1, 58},
+	"percent":      {0.1, 66},
+}
+
+var defaultDetectHint = detectHint{1, 64}
+
+// DetectEncoding guesses which registered encoding produced s, for
+// diagnostic tools. It returns the name of the most likely one, and how
+// sure it is, from 0 to 1. If none of them could have produced s (or s is
+// empty), the name is "" and the confidence 0.
+//
+// An encoding is a candidate if it would give back exactly s when encoding
+// the bytes it decodes from s (ignoring case for hex), so eg. base64 with
+// trailing garbage bits is not. The candidates are then weighed by how
+// likely a random string of that encoding is to be s: "deadbeef" is very
+// probably hex, while "Zm9vYmFy" is equally likely base64 or rawbase64,
+// giving each a confidence of about 0.5.
+//
+// Aliases (an encoder registered under several names) count once, with
+// their first name.
+func DetectEncoding(s string) (name string, confidence float64) {
+	src, err := json.Marshal(s)
+	if s == "" || err != nil {
+		return "", 0
+	}
+	var names []string
+	var logWeights []float64
+	for _, n := range registeredNames() {
+		enc, ok := EncoderByName(n)
+		if !ok {
+			continue
+		}
+		if first, ok := EncoderName(enc); ok && first != n {
+			continue
+		}
+		var decoded []byte
+		if enc.Unmarshal(&decoded, src) != nil || !Bytes(decoded).EqualEncoded(s, enc) {
+			continue
+		}
+		hint, ok := detectHints[n]
+		if !ok {
+			hint = defaultDetectHint
+		}
+		names = append(names, n)
+		logWeights = append(logWeights, math.Log(hint.prior)-float64(len(s))*math.Log(hint.alphabet))
+	}
+	if len(names) == 0 {
+		return "", 0
+	}
+
+	// normalize in log space, as the weights underflow for long strings
+	best := 0
+	for i, w := range logWeights {
+		if w > logWeights[best] {
+			best = i
+		}
+	}
+	total := 0.0
+	for _, w := range logWeights {
+		total += math.Exp(w - logWeights[best])
+	}
+	return names[best], 1 / total
+}
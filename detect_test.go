@@ -0,0 +1,50 @@
+package data_test
+
+import (
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		input string
+		name  string
+		// minimum and maximum confidence
+		min, max float64
+	}{
+		// unambiguous
+		{"deadbeef", "hex", 0.99, 1},
+		{"1A2B3C4D5E6F", "hex", 0.99, 1},
+		{"RCEuM3M=", "base64", 1, 1},
+		{"D4_a--0", "rawbase64", 0.9, 1},
+		{"a%20b%2Fc", "percent", 1, 1},
+		// base64 or rawbase64, without padding to tell them apart
+		{"Zm9vYmFy", "base64", 0.4, 0.5},
+		// a short word could be rawbase64 or percent encoded text
+		{"hellow", "rawbase64", 0.5, 0.95},
+		{"hello", "percent", 1, 1},
+		// nothing matches
+		{"", "", 0, 0},
+		{"hey!", "", 0, 0},
+		{"a b", "", 0, 0},
+		{`say "hi"`, "", 0, 0},
+		// trailing bits are not canonical base64
+		{"Zm9=", "", 0, 0},
+	}
+
+	for _, tc := range cases {
+		name, confidence := data.DetectEncoding(tc.input)
+		assert.Equal(tc.name, name, tc.input)
+		assert.True(confidence >= tc.min && confidence <= tc.max,
+			"%s: confidence %f not in [%f, %f]", tc.input, confidence, tc.min, tc.max)
+	}
+
+	// ambiguous inputs are less certain
+	_, sure := data.DetectEncoding("RCEuM3M=")
+	_, unsure := data.DetectEncoding("Zm9vYmFy")
+	assert.True(unsure < sure)
+}
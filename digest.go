@@ -0,0 +1,44 @@
+package data
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// ErrOneWay is returned by the Unmarshal of one-way encoders like
+// DigestEncoder
+var ErrOneWay = errors.New("one-way encoding, the bytes can't be recovered")
+
+// DigestEncoder marshals bytes as the uppercase hex digest of them under
+// Algo (SHA256 if unset), eg. for search indices that should be able to
+// find a value without storing it. The digest is the same as Bytes.Hash,
+// shown like HexEncoder would.
+//
+// This is lossy and one-way: Unmarshal always fails with ErrOneWay. Only
+// use it in structs that are written, never read back. Also note that
+// short or guessable values can be found again from their digest by trying
+// all candidates, so this does not keep secrets.
+type DigestEncoder struct {
+	Algo HashAlgo
+}
+
+func (e DigestEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (e DigestEncoder) Unmarshal(dst *[]byte, src []byte) error {
+	return errors.WithStack(ErrOneWay)
+}
+
+func (e DigestEncoder) Marshal(bytes []byte) ([]byte, error) {
+	algo := e.Algo
+	switch algo {
+	case 0:
+		algo = SHA256
+	case SHA256, SHA512, Keccak256, RIPEMD160:
+	default:
+		return nil, errors.Errorf("Unknown hash algorithm: %s", algo)
+	}
+	return json.Marshal(Bytes(bytes).Hash(algo).HexGrouped(0, ""))
+}
@@ -0,0 +1,58 @@
+package data_test
+
+import (
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigestEncoder(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cases := []struct {
+		encoder  data.DigestEncoder
+		input    data.Bytes
+		expected string
+	}{
+		{data.DigestEncoder{}, data.Bytes("hello"),
+			`"2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824"`},
+		{data.DigestEncoder{Algo: data.SHA256}, data.Bytes("hello"),
+			`"2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824"`},
+		{data.DigestEncoder{Algo: data.SHA256}, nil,
+			`"E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855"`},
+		{data.DigestEncoder{Algo: data.SHA512}, data.Bytes("hello"),
+			`"9B71D224BD62F3785D96D46AD3EA3D73319BFBC2890CAADAE2DFF72519673CA72323C3D99BA5C11D7C7ACC6E14B8C5DA0C4663475C2E5C3ADEF46F73BCDEC043"`},
+	}
+
+	for _, tc := range cases {
+		out, err := tc.encoder.Marshal(tc.input)
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.expected, string(out))
+
+		// the same as the hash, with the hex encoder
+		algo := tc.encoder.Algo
+		if algo == 0 {
+			algo = data.SHA256
+		}
+		hexed, err := data.HexEncoder.Marshal(tc.input.Hash(algo))
+		require.Nil(err)
+		assert.Equal(hexed, out)
+
+		// and can't be reversed
+		var back []byte
+		err = tc.encoder.Unmarshal(&back, out)
+		assert.True(errors.Is(err, data.ErrOneWay), "%+v", err)
+		assert.Nil(back)
+	}
+
+	// in a struct, with MarshalJSONWith
+	out, err := data.MarshalJSONWith(struct{ Email data.Bytes }{data.Bytes("hello")}, data.DigestEncoder{})
+	require.Nil(err)
+	assert.Equal(`{"Email":"2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824"}`, string(out))
+
+	_, err = data.DigestEncoder{Algo: data.HashAlgo(42)}.Marshal([]byte("hello"))
+	assert.NotNil(err)
+}
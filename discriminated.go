@@ -0,0 +1,37 @@
+package data
+
+import "github.com/pkg/errors"
+
+// Discriminated packs byte unions, where a leading tag byte says which of
+// several layouts the rest of the bytes (the payload) follows:
+//
+//	raw := data.Discriminated.Marshal(kindPubKey, pub)
+//	tag, payload, err := data.Discriminated.Unmarshal(raw)
+//
+// What the tags mean is up to the caller, any byte value is allowed.
+var Discriminated = discriminated{}
+
+// discriminated implements the tag byte prefix for Discriminated
+type discriminated struct{}
+
+// Marshal returns tag followed by payload, as a new slice
+func (discriminated) Marshal(tag byte, payload Bytes) Bytes {
+	res := make(Bytes, 1+len(payload))
+	res[0] = tag
+	copy(res[1:], payload)
+	return res
+}
+
+// Unmarshal splits the output of Marshal into the tag and the payload. The
+// payload is a copy, not a slice of b, and may be empty. Empty input has no
+// tag byte, and is a TooShortError.
+func (discriminated) Unmarshal(b Bytes) (tag byte, payload Bytes, err error) {
+	if len(b) == 0 {
+		err = errors.WithStack(TooShortError{Len: 0, Min: 1})
+		return 0, nil, formatHook("discriminated", 0, nil, err)
+	}
+	if err := formatHook("discriminated", len(b), b[1:], nil); err != nil {
+		return 0, nil, err
+	}
+	return b[0], append(Bytes{}, b[1:]...), nil
+}
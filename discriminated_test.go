@@ -0,0 +1,55 @@
+package data_test
+
+import (
+	"bytes"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscriminated(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		tag      byte
+		payload  data.Bytes
+		expected data.Bytes
+	}{
+		{0x00, data.Bytes{0x1a, 0x2b}, data.Bytes{0x00, 0x1a, 0x2b}},
+		{0x01, data.Bytes("D!.3s"), data.Bytes("\x01D!.3s")},
+		{0x7f, data.Bytes{0xff}, data.Bytes{0x7f, 0xff}},
+		{0xff, data.Bytes{0x00, 0x00}, data.Bytes{0xff, 0x00, 0x00}},
+		// empty payloads
+		{0x02, data.Bytes{}, data.Bytes{0x02}},
+		{0x03, nil, data.Bytes{0x03}},
+	}
+
+	for _, tc := range cases {
+		raw := data.Discriminated.Marshal(tc.tag, tc.payload)
+		assert.Equal(tc.expected, raw, "%X", tc.expected)
+
+		tag, payload, err := data.Discriminated.Unmarshal(raw)
+		if assert.Nil(err, "%+v", err) {
+			assert.Equal(tc.tag, tag)
+			assert.True(bytes.Equal(tc.payload, payload), "%X", payload)
+		}
+	}
+
+	// the payload does not alias the input
+	raw := data.Bytes{0x05, 0x1a, 0x2b}
+	_, payload, err := data.Discriminated.Unmarshal(raw)
+	assert.Nil(err)
+	raw[1] = 0
+	assert.Equal(data.Bytes{0x1a, 0x2b}, payload)
+
+	// no tag byte
+	for _, raw := range []data.Bytes{nil, {}} {
+		_, _, err := data.Discriminated.Unmarshal(raw)
+		var short data.TooShortError
+		if assert.True(errors.As(err, &short), "%+v", err) {
+			assert.Equal(1, short.Min)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package data
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// diffWindow is how many bytes Diff shows on each side of the first
+// difference
+const diffWindow = 8
+
+// maxHexDumpRepeat is how far ParseHexDump expands a repeat marker
+const maxHexDumpRepeat = 64 << 20
+
+// HexGrouped renders the bytes as uppercase hex, with sep inserted after
+// every groupSize bytes, eg. "1A2B 3C4D" for groupSize 2 and sep " ".
+//
+// This is for display only, there is no matching decoder.
+// A groupSize <= 0 disables grouping.
+func (b Bytes) HexGrouped(groupSize int, sep string) string {
+	s := strings.ToUpper(hex.EncodeToString(b))
+	width := 2 * groupSize
+	if groupSize <= 0 || len(s) <= width {
+		return s
+	}
+	var res strings.Builder
+	for i := 0; i < len(s); i += width {
+		if i > 0 {
+			res.WriteString(sep)
+		}
+		end := i + width
+		if end > len(s) {
+			end = len(s)
+		}
+		res.WriteString(s[i:end])
+	}
+	return res.String()
+}
+
+// HexWrapped renders the bytes as uppercase hex, split into lines of at
+// most cols characters, eg. to print long hashes in a narrow terminal.
+// Lines never split a byte, so an odd cols leaves one column unused, and a
+// cols below 2 puts every byte on its own line. There is no trailing
+// newline.
+//
+// This is for display only, there is no matching decoder.
+func (b Bytes) HexWrapped(cols int) string {
+	perLine := cols / 2
+	if perLine < 1 {
+		perLine = 1
+	}
+	return b.HexGrouped(perLine, "\n")
+}
+
+// SafeDisplay renders the bytes as text that is safe to print to a
+// terminal or log, like %q without the quotes: printable characters
+// (including non-ascii ones) are kept, and control characters, invalid
+// UTF-8 and other non-printable runes (like the bidi overrides) are escaped
+// as \xNN for each byte. A backslash becomes \\, so the escapes are
+// unambiguous.
+//
+// This is for display only, there is no matching decoder.
+func (b Bytes) SafeDisplay() string {
+	var res strings.Builder
+	res.Grow(len(b))
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		switch {
+		case r == '\\':
+			res.WriteString(`\\`)
+		case r == utf8.RuneError && size == 1, !unicode.IsPrint(r):
+			for _, c := range b[:size] {
+				fmt.Fprintf(&res, `\x%02x`, c)
+			}
+		default:
+			res.Write(b[:size])
+		}
+		b = b[size:]
+	}
+	return res.String()
+}
+
+// Diff returns a human-readable description of where a and b differ, or
+// "" if they are equal.
+//
+// It shows the hex of both values aligned one above the other, around the
+// first differing offset, which is marked with brackets. Bytes past the end
+// of the shorter value are shown as "--":
+//
+//	first difference at offset 2 (len 4 vs 3)
+//	a[0:4]: 01 02 [03] 04
+//	b[0:4]: 01 02 [05] --
+func Diff(a, b Bytes) string {
+	if bytes.Equal(a, b) {
+		return ""
+	}
+	off := 0
+	for off < len(a) && off < len(b) && a[off] == b[off] {
+		off++
+	}
+	start, end := off-diffWindow, off+diffWindow+1
+	if start < 0 {
+		start = 0
+	}
+	if end > len(a) && end > len(b) {
+		end = len(a)
+		if len(b) > end {
+			end = len(b)
+		}
+	}
+	return fmt.Sprintf("first difference at offset %d (len %d vs %d)\na[%d:%d]: %s\nb[%d:%d]: %s\n",
+		off, len(a), len(b),
+		start, end, hexWindow(a, start, end, off),
+		start, end, hexWindow(b, start, end, off))
+}
+
+// hexWindow renders b[start:end] with mark in brackets, using "--"
+// for offsets past the end of b
+func hexWindow(b Bytes, start, end, mark int) string {
+	parts := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		s := "--"
+		if i < len(b) {
+			s = fmt.Sprintf("%02X", b[i])
+		}
+		if i == mark {
+			s = "[" + s + "]"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseHexDump recovers the bytes from the output of `hexdump -C`, like:
+//
+//	00000000  1a 2b 3c 4d 5e 6f 70 81  92 a3 b4 c5 d6 e7 f8 09  |.+<M^op.........|
+//	00000010  00 11 22                                          |.."|
+//	00000013
+//
+// It ignores the ascii panel, and uses the offset column to check that no
+// lines are missing. A "*" line (repeats of the previous line) is expanded
+// up to the offset of the next line, which must be a whole number of lines
+// further, and at most 64 MiB into the value, so a forged offset can't
+// exhaust memory.
+func ParseHexDump(s string) (Bytes, error) {
+	res, err := parseHexDump(s)
+	err = formatHook("hexdump", len(s), res, err)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func parseHexDump(s string) (Bytes, error) {
+	res := Bytes{}
+	var prev Bytes
+	repeat := false
+	for n, line := range strings.Split(s, "\n") {
+		if i := strings.IndexByte(line, '|'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) == 1 && fields[0] == "*" {
+			if prev == nil || repeat {
+				return nil, errors.Errorf("Line %d: unexpected repeat marker", n+1)
+			}
+			repeat = true
+			continue
+		}
+		offset, err := strconv.ParseUint(strings.TrimSuffix(fields[0], ":"), 16, 64)
+		if err != nil {
+			return nil, errors.Errorf("Line %d: invalid offset %q", n+1, fields[0])
+		}
+		if repeat {
+			if offset > maxHexDumpRepeat {
+				return nil, errors.Errorf("Line %d: repeat up to offset %x exceeds %d bytes", n+1, offset, maxHexDumpRepeat)
+			}
+			if offset < uint64(len(res)) || (offset-uint64(len(res)))%uint64(len(prev)) != 0 {
+				return nil, errors.Errorf("Line %d: offset %x is not a whole number of repeats from %x", n+1, offset, len(res))
+			}
+			for uint64(len(res)) < offset {
+				res = append(res, prev...)
+			}
+		}
+		repeat = false
+		if offset != uint64(len(res)) {
+			return nil, errors.Errorf("Line %d: offset %x, expected %x", n+1, offset, len(res))
+		}
+		row := make(Bytes, 0, len(fields)-1)
+		for _, f := range fields[1:] {
+			b, err := hex.DecodeString(f)
+			if err != nil || len(b) != 1 {
+				return nil, errors.Errorf("Line %d: invalid byte %q", n+1, f)
+			}
+			row = append(row, b[0])
+		}
+		res = append(res, row...)
+		if len(row) > 0 {
+			prev = row
+		}
+	}
+	if repeat {
+		return nil, errors.New("Repeat marker without a following offset")
+	}
+	return res, nil
+}
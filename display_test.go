@@ -0,0 +1,194 @@
+package data_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHexGrouped(t *testing.T) {
+	assert := assert.New(t)
+
+	b := data.Bytes{0x1a, 0x2b, 0x3c, 0x4d, 0x5e}
+	cases := []struct {
+		bytes     data.Bytes
+		groupSize int
+		sep       string
+		expected  string
+	}{
+		{b, 1, " ", "1A 2B 3C 4D 5E"},
+		{b, 2, " ", "1A2B 3C4D 5E"},
+		{b, 2, ":", "1A2B:3C4D:5E"},
+		{b, 4, " - ", "1A2B3C4D - 5E"},
+		{b, 5, " ", "1A2B3C4D5E"},
+		// larger than the value
+		{b, 16, " ", "1A2B3C4D5E"},
+		// no grouping
+		{b, 0, " ", "1A2B3C4D5E"},
+		{b, -3, " ", "1A2B3C4D5E"},
+		{data.Bytes{}, 2, " ", ""},
+		{nil, 2, " ", ""},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(tc.expected, tc.bytes.HexGrouped(tc.groupSize, tc.sep),
+			"%d %q", tc.groupSize, tc.sep)
+	}
+}
+
+func TestHexWrapped(t *testing.T) {
+	assert := assert.New(t)
+
+	b := data.Bytes{0x1a, 0x2b, 0x3c, 0x4d, 0x5e, 0x6f}
+	cases := []struct {
+		bytes    data.Bytes
+		cols     int
+		expected string
+	}{
+		// shorter than the width
+		{b, 80, "1A2B3C4D5E6F"},
+		{b, 12, "1A2B3C4D5E6F"},
+		// exact multiples
+		{b, 4, "1A2B\n3C4D\n5E6F"},
+		{b, 6, "1A2B3C\n4D5E6F"},
+		// remainders
+		{b, 8, "1A2B3C4D\n5E6F"},
+		{b, 10, "1A2B3C4D5E\n6F"},
+		// odd widths don't split bytes
+		{b, 5, "1A2B\n3C4D\n5E6F"},
+		{b, 2, "1A\n2B\n3C\n4D\n5E\n6F"},
+		{b, 1, "1A\n2B\n3C\n4D\n5E\n6F"},
+		{b, 0, "1A\n2B\n3C\n4D\n5E\n6F"},
+		{data.Bytes{}, 4, ""},
+		{nil, 4, ""},
+	}
+
+	for _, tc := range cases {
+		res := tc.bytes.HexWrapped(tc.cols)
+		assert.Equal(tc.expected, res, "%d", tc.cols)
+		for _, line := range strings.Split(res, "\n") {
+			assert.True(len(line) <= tc.cols || len(line) == 2, "%d: %q", tc.cols, line)
+		}
+	}
+}
+
+func TestSafeDisplay(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		input    data.Bytes
+		expected string
+	}{
+		{nil, ""},
+		{data.Bytes("hello, world! \"quoted\" 'too' ~{}"), "hello, world! \"quoted\" 'too' ~{}"},
+		// control characters
+		{data.Bytes("line\nbreak\ttab\r"), `line\x0abreak\x09tab\x0d`},
+		{data.Bytes("\x00\x1b[31mred\x7f"), `\x00\x1b[31mred\x7f`},
+		// printable non-ascii is kept, others are escaped byte by byte
+		{data.Bytes("caf\u00e9 \u4e16"), "caf\u00e9 \u4e16"},
+		{data.Bytes("a\u202eb"), `a\xe2\x80\xaeb`},
+		{data.Bytes("\u0085"), `\xc2\x85`},
+		// invalid UTF-8
+		{data.Bytes{0xff, 'a', 0xc3}, `\xffa\xc3`},
+		{data.Bytes{0xe4, 0xb8, 'x'}, `\xe4\xb8x`},
+		{data.Bytes{0xc0, 0xaf}, `\xc0\xaf`}, // overlong
+		// backslashes are escaped too
+		{data.Bytes(`C:\x41`), `C:\\x41`},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(tc.expected, tc.input.SafeDisplay(), "%q", []byte(tc.input))
+	}
+}
+
+func TestDiff(t *testing.T) {
+	assert := assert.New(t)
+
+	long := data.Bytes(bytes.Repeat([]byte{0xaa}, 40))
+	changed := append(data.Bytes{}, long...)
+	changed[20] = 0xbb
+
+	cases := []struct {
+		a, b     data.Bytes
+		expected string
+	}{
+		// identical
+		{data.Bytes{0x01, 0x02}, data.Bytes{0x01, 0x02}, ""},
+		{nil, data.Bytes{}, ""},
+		// single byte
+		{data.Bytes{0x01, 0x02, 0x03, 0x04}, data.Bytes{0x01, 0x02, 0x05, 0x04},
+			"first difference at offset 2 (len 4 vs 4)\n" +
+				"a[0:4]: 01 02 [03] 04\n" +
+				"b[0:4]: 01 02 [05] 04\n"},
+		// window around the difference
+		{long, changed,
+			"first difference at offset 20 (len 40 vs 40)\n" +
+				"a[12:29]: AA AA AA AA AA AA AA AA [AA] AA AA AA AA AA AA AA AA\n" +
+				"b[12:29]: AA AA AA AA AA AA AA AA [BB] AA AA AA AA AA AA AA AA\n"},
+		// different lengths
+		{data.Bytes{0x01, 0x02, 0x03}, data.Bytes{0x01, 0x02},
+			"first difference at offset 2 (len 3 vs 2)\n" +
+				"a[0:3]: 01 02 [03]\n" +
+				"b[0:3]: 01 02 [--]\n"},
+		{data.Bytes{}, data.Bytes{0xff},
+			"first difference at offset 0 (len 0 vs 1)\n" +
+				"a[0:1]: [--]\n" +
+				"b[0:1]: [FF]\n"},
+	}
+
+	for i, tc := range cases {
+		assert.Equal(tc.expected, data.Diff(tc.a, tc.b), "%d", i)
+	}
+}
+
+func TestParseHexDump(t *testing.T) {
+	assert := assert.New(t)
+
+	dump := `00000000  1a 2b 3c 4d 5e 6f 70 81  92 a3 b4 c5 d6 e7 f8 09  |.+<M^op.........|
+00000010  00 11 22 7c                                       |.."||
+00000014
+`
+	expected := data.Bytes{0x1a, 0x2b, 0x3c, 0x4d, 0x5e, 0x6f, 0x70, 0x81,
+		0x92, 0xa3, 0xb4, 0xc5, 0xd6, 0xe7, 0xf8, 0x09, 0x00, 0x11, 0x22, 0x7c}
+
+	repeated := `00000000  00 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+*
+00000030  ff                                                |.|
+00000031`
+	zeros := append(data.Bytes(bytes.Repeat([]byte{0}, 48)), 0xff)
+
+	cases := []struct {
+		dump     string
+		expected data.Bytes
+	}{
+		{dump, expected},
+		// works without trailing offset and ascii panel
+		{"00000000  de ad be ef\n00000004  66", data.Bytes{0xde, 0xad, 0xbe, 0xef, 0x66}},
+		{repeated, zeros},
+		{"", data.Bytes{}},
+		// malformed lines
+		{"00000000  1a 2b 3c 4x", nil},
+		{"00000000  1a2b 3c4d", nil},
+		{"xyz  1a 2b", nil},
+		// missing line
+		{"00000000  1a 2b\n00000004  3c", nil},
+		{"*\n00000010", nil},
+		{"00000000  1a 2b\n*", nil},
+		// repeats that don't fit the next offset, or too many of them
+		{"00000000  1a 2b 3c\n*\n00000004  3c", nil},
+		{"00000000  1a 2b\n*\n00000001  3c", nil},
+		{"00000000  " + strings.Repeat("00 ", 16) + "\n*\n0000000040000000\n", nil},
+	}
+
+	for i, tc := range cases {
+		res, err := data.ParseHexDump(tc.dump)
+		if tc.expected == nil {
+			assert.NotNil(err, "%d", i)
+		} else if assert.Nil(err, "%d: %+v", i, err) {
+			assert.Equal(tc.expected, res, "%d", i)
+		}
+	}
+}
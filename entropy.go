@@ -0,0 +1,30 @@
+package data
+
+import "math"
+
+// ShannonEntropy returns the Shannon entropy of the byte values in b, in
+// bits per byte: 0 if all bytes are the same (or b is empty), up to 8 if
+// all 256 values are equally common.
+//
+// It only looks at how often each value occurs, not at their order, so
+// {0, 1, 2, ..., 255} scores a perfect 8. Use it as a warning sign for
+// keys that look structured, not as proof that a value is random. Short
+// values can't score high, as n bytes have at most log2(n) bits each.
+func (b Bytes) ShannonEntropy() float64 {
+	if len(b) == 0 {
+		return 0
+	}
+	var counts [256]int
+	for _, c := range b {
+		counts[c]++
+	}
+	n := float64(len(b))
+	res := 0.0
+	for _, count := range counts {
+		if count > 0 {
+			p := float64(count) / n
+			res -= p * math.Log2(p)
+		}
+	}
+	return res
+}
@@ -0,0 +1,55 @@
+package data_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShannonEntropy(t *testing.T) {
+	assert := assert.New(t)
+
+	all := make(data.Bytes, 256)
+	for i := range all {
+		all[i] = byte(i)
+	}
+	cases := []struct {
+		input    data.Bytes
+		expected float64
+	}{
+		// all the same
+		{nil, 0},
+		{data.Bytes{0x42}, 0},
+		{data.Bytes(bytes.Repeat([]byte{0x00}, 32)), 0},
+		// known distributions
+		{data.Bytes{0x00, 0x01}, 1},
+		{data.Bytes("abab"), 1},
+		{data.Bytes("abcd"), 2},
+		// p = 1/2, 1/4, 1/4
+		{data.Bytes("aabc"), 1.5},
+		// p = 3/4, 1/4
+		{data.Bytes("aaab"), 0.8112781244591328},
+		{all, 8},
+		{data.Bytes(bytes.Repeat(all, 4)), 8},
+	}
+
+	for _, tc := range cases {
+		assert.InDelta(tc.expected, tc.input.ShannonEntropy(), 1e-9, "%X", tc.input)
+	}
+
+	// order doesn't matter
+	assert.InDelta(data.Bytes("aabc").ShannonEntropy(), data.Bytes("cbaa").ShannonEntropy(), 1e-12)
+}
+
+func TestShannonEntropyRandom(t *testing.T) {
+	key := make(data.Bytes, 64<<10)
+	_, err := rand.Read(key)
+	require.Nil(t, err)
+	// close to 8, but random data never hits it exactly
+	e := key.ShannonEntropy()
+	assert.True(t, e > 7.99 && e < 8, "%f", e)
+}
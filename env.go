@@ -0,0 +1,67 @@
+package data
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// EncoderEnv is the environment variable read by ConfigureFromEnv
+const EncoderEnv = "DATA_ENCODER"
+
+// ErrEnvNotSet is returned by BytesFromEnv if the variable is not set
+var ErrEnvNotSet = errors.New("environment variable not set")
+
+// BytesFromEnv reads bytes from the environment variable key.
+//
+// The value may start with the name of a registered encoder and a colon,
+// like "hex:1A2B" or "base64:GiM=", to choose the encoding. Without such a
+// prefix it is decoded as hex. If the variable is not set at all, the
+// error is ErrEnvNotSet (use errors.Is), while an empty value is empty
+// bytes.
+func BytesFromEnv(key string) (Bytes, error) {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return nil, errors.Wrap(ErrEnvNotSet, key)
+	}
+	var enc ByteEncoder = HexEncoder
+	if i := strings.IndexByte(val, ':'); i >= 0 {
+		e, ok := EncoderByName(val[:i])
+		if !ok {
+			return nil, errors.Errorf("%s: unknown encoder %q", key, val[:i])
+		}
+		enc, val = e, val[i+1:]
+	}
+	quoted, err := json.Marshal(val)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var res []byte
+	err = unmarshalHooked(enc, &res, quoted)
+	if err != nil {
+		return nil, errors.Wrap(err, key)
+	}
+	return Bytes(res), nil
+}
+
+// ConfigureFromEnv sets the global Encoder to the registered encoder named
+// in the DATA_ENCODER environment variable, like "hex", "base64" or
+// "rawbase64". If it is unset or empty, Encoder is left alone.
+//
+// It is meant to be called early in main, after any encoders from other
+// packages have been registered. An unknown name is an error and leaves
+// Encoder unchanged, so the caller can decide whether that is fatal.
+func ConfigureFromEnv() error {
+	name := strings.TrimSpace(os.Getenv(EncoderEnv))
+	if name == "" {
+		return nil
+	}
+	enc, ok := EncoderByName(name)
+	if !ok {
+		return errors.Errorf("%s: unknown encoder %q", EncoderEnv, name)
+	}
+	Encoder = enc
+	return nil
+}
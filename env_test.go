@@ -0,0 +1,98 @@
+package data_test
+
+import (
+	"os"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBytesFromEnv(t *testing.T) {
+	assert := assert.New(t)
+
+	const key = "DATA_GO_TEST_BYTES"
+	defer os.Unsetenv(key)
+
+	cases := []struct {
+		value    string
+		expected data.Bytes
+	}{
+		{"hex:1A2B", data.Bytes{0x1a, 0x2b}},
+		{"hex:1a2b", data.Bytes{0x1a, 0x2b}},
+		{"base64:RCEuM3M=", data.Bytes("D!.3s")},
+		{"rawbase64:RCEuM3M", data.Bytes("D!.3s")},
+		// bare hex
+		{"DEADBEEF", data.Bytes{0xde, 0xad, 0xbe, 0xef}},
+		{"", data.Bytes{}},
+		// errors
+		{"base64:hey!", nil},
+		{"1a2", nil},
+		{"nosuch:1a2b", nil},
+		{"RCEuM3M=", nil},
+	}
+
+	for _, tc := range cases {
+		os.Setenv(key, tc.value)
+		res, err := data.BytesFromEnv(key)
+		if tc.expected == nil {
+			assert.NotNil(err, tc.value)
+			assert.False(errors.Is(err, data.ErrEnvNotSet), tc.value)
+		} else if assert.Nil(err, "%s: %+v", tc.value, err) {
+			assert.Equal(tc.expected, res, tc.value)
+		}
+	}
+
+	// missing is different from empty
+	os.Unsetenv(key)
+	_, err := data.BytesFromEnv(key)
+	assert.True(errors.Is(err, data.ErrEnvNotSet), "%+v", err)
+}
+
+func TestConfigureFromEnv(t *testing.T) {
+	assert := assert.New(t)
+
+	defer data.SaveEncoder()()
+	defer os.Unsetenv(data.EncoderEnv)
+
+	cases := []struct {
+		value    string
+		expected data.ByteEncoder
+	}{
+		{"hex", data.HexEncoder},
+		{"base64", data.B64Encoder},
+		{"rawbase64", data.RawB64Encoder},
+		{"percent", data.PercentEncoder},
+		{" base64\n", data.B64Encoder},
+	}
+
+	for _, tc := range cases {
+		data.Encoder = data.HexFormat{Lower: true}
+		os.Setenv(data.EncoderEnv, tc.value)
+		err := data.ConfigureFromEnv()
+		if assert.Nil(err, "%s: %+v", tc.value, err) {
+			assert.Equal(tc.expected, data.Encoder, tc.value)
+		}
+	}
+
+	// unknown names are an error, and change nothing
+	for _, value := range []string{"base63", "HEX", "hex:"} {
+		data.Encoder = data.B64Encoder
+		os.Setenv(data.EncoderEnv, value)
+		err := data.ConfigureFromEnv()
+		if assert.NotNil(err, value) {
+			assert.Contains(err.Error(), data.EncoderEnv)
+		}
+		assert.Equal(data.B64Encoder, data.Encoder, value)
+	}
+
+	// unset or empty keeps the default
+	data.Encoder = data.RawB64Encoder
+	os.Setenv(data.EncoderEnv, "")
+	assert.Nil(data.ConfigureFromEnv())
+	assert.Equal(data.RawB64Encoder, data.Encoder)
+	os.Unsetenv(data.EncoderEnv)
+	assert.Nil(data.ConfigureFromEnv())
+	assert.Equal(data.RawB64Encoder, data.Encoder)
+}
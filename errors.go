@@ -0,0 +1,125 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// NotStringError is returned when decoding bytes from a json value that
+// is not a string (or null). Kind is the kind of json value we got, one
+// of "number", "object", "array", "boolean" or "invalid".
+type NotStringError struct {
+	Kind string
+}
+
+func (e NotStringError) Error() string {
+	return fmt.Sprintf("expected a json string, got %s", e.Kind)
+}
+
+// TooShortError is returned when decoded bytes are shorter than allowed
+type TooShortError struct {
+	Len, Min int
+}
+
+func (e TooShortError) Error() string {
+	return fmt.Sprintf("too short: %d bytes, need at least %d", e.Len, e.Min)
+}
+
+// TooLongError is returned when decoded bytes are longer than allowed
+type TooLongError struct {
+	Len, Max int
+}
+
+func (e TooLongError) Error() string {
+	return fmt.Sprintf("too long: %d bytes, allowed at most %d", e.Len, e.Max)
+}
+
+// FieldError adds the name of the field (or any other context label) to
+// a decoding error. It unwraps to the original error, so errors.Is and
+// errors.As still find eg. a NotStringError.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// WrapFieldError labels err with field, returning a FieldError.
+// It returns nil if err is nil.
+func WrapFieldError(field string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return FieldError{Field: field, Err: err}
+}
+
+// ParseJSONString parses src as a json string, which all the ByteEncoders
+// use to hold the encoded text. null is parsed as "".
+//
+// If src is some other json value, it returns a NotStringError, so custom
+// ByteEncoders should use this as well to give the same errors.
+func ParseJSONString(src []byte) (string, error) {
+	trimmed := bytes.TrimSpace(src)
+	if len(trimmed) > 0 && trimmed[0] != '"' && !bytes.Equal(trimmed, []byte("null")) {
+		return "", errors.WithStack(NotStringError{Kind: jsonKind(trimmed)})
+	}
+	var s string
+	err := json.Unmarshal(src, &s)
+	if err != nil {
+		return "", errors.Wrap(err, "parse string")
+	}
+	return s, nil
+}
+
+// jsonText is like ParseJSONString, but returns the text as bytes. For
+// strings without escapes (which all our encoders produce) it returns the
+// part of src between the quotes, without copying.
+//
+// This lets decoders validate large inputs before allocating anything, so
+// it must not be kept or modified.
+func jsonText(src []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(src)
+	if len(trimmed) >= 2 && trimmed[0] == '"' && trimmed[len(trimmed)-1] == '"' {
+		text := trimmed[1 : len(trimmed)-1]
+		if isPlainJSON(text) {
+			return text, nil
+		}
+	}
+	s, err := ParseJSONString(src)
+	return []byte(s), err
+}
+
+// isPlainJSON returns true if text can appear in a json string as is
+func isPlainJSON(text []byte) bool {
+	for _, c := range text {
+		if c < 0x20 || c == '"' || c == '\\' {
+			return false
+		}
+	}
+	return utf8.Valid(text)
+}
+
+// jsonKind guesses the kind of json value from its first character
+func jsonKind(src []byte) string {
+	switch c := src[0]; {
+	case c == '{':
+		return "object"
+	case c == '[':
+		return "array"
+	case c == 't', c == 'f':
+		return "boolean"
+	case c == '-', '0' <= c && c <= '9':
+		return "number"
+	}
+	return "invalid"
+}
@@ -0,0 +1,104 @@
+package data_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotStringError(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	encoders := []data.ByteEncoder{
+		data.HexEncoder,
+		data.B64Encoder,
+		data.RawB64Encoder,
+		data.PercentEncoder,
+		data.Base32CheckEncoder,
+	}
+	cases := []struct {
+		input string
+		kind  string
+	}{
+		{`0123`, "number"},
+		{`-5.2`, "number"},
+		{`{"bytes":"1a2b"}`, "object"},
+		{` [26, 43]`, "array"},
+		{`true`, "boolean"},
+		{`false`, "boolean"},
+		{`'1a2b'`, "invalid"},
+	}
+
+	for _, enc := range encoders {
+		for _, tc := range cases {
+			var output []byte
+			err := enc.Unmarshal(&output, []byte(tc.input))
+			require.NotNil(err, tc.input)
+			var nse data.NotStringError
+			if assert.True(errors.As(err, &nse), "%T %s: %+v", enc, tc.input, err) {
+				assert.Equal(tc.kind, nse.Kind, tc.input)
+			}
+			assert.Contains(err.Error(), tc.kind, tc.input)
+		}
+	}
+
+	// also for Bytes fields in a struct
+	data.Encoder = data.HexEncoder
+	var parsed BData
+	err := json.Unmarshal([]byte(`{"Count": 1, "Data": {"a": 1}}`), &parsed)
+	require.NotNil(err)
+	var nse data.NotStringError
+	require.True(errors.As(err, &nse), "%+v", err)
+	assert.Equal("object", nse.Kind)
+	assert.Equal("expected a json string, got object", err.Error())
+
+	// strings and null are fine
+	for _, input := range []string{`"1a2b"`, ` "1a2b" `, `null`} {
+		var output []byte
+		err := data.HexEncoder.Unmarshal(&output, []byte(input))
+		assert.Nil(err, "%s: %+v", input, err)
+	}
+	// broken strings are not a NotStringError
+	var output []byte
+	err = data.HexEncoder.Unmarshal(&output, []byte(`"1a2b`))
+	require.NotNil(err)
+	assert.False(errors.As(err, &nse))
+}
+
+func TestWrapFieldError(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	var output []byte
+	err := data.HexEncoder.Unmarshal(&output, []byte(`{"a":1}`))
+	require.NotNil(err)
+
+	wrapped := data.WrapFieldError("pubkey", err)
+	assert.Equal("pubkey: expected a json string, got object", wrapped.Error())
+	// still finds the typed error
+	var nse data.NotStringError
+	require.True(errors.As(wrapped, &nse))
+	assert.Equal("object", nse.Kind)
+	// and the field
+	var fe data.FieldError
+	require.True(errors.As(wrapped, &fe))
+	assert.Equal("pubkey", fe.Field)
+
+	// nested labels, and with errors.Is
+	nested := data.WrapFieldError("tx", data.WrapFieldError("sigs[0]", data.ErrTooDeep))
+	assert.Equal("tx: sigs[0]: json document too deeply nested", nested.Error())
+	assert.True(errors.Is(nested, data.ErrTooDeep))
+	assert.False(errors.Is(nested, data.ErrTooLarge))
+
+	assert.Nil(data.WrapFieldError("pubkey", nil))
+}
+
+func TestLengthErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("too short: 1 bytes, need at least 4", data.TooShortError{Len: 1, Min: 4}.Error())
+	assert.Equal("too long: 9 bytes, allowed at most 8", data.TooLongError{Len: 9, Max: 8}.Error())
+}
@@ -0,0 +1,109 @@
+package data
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"strings"
+
+	"golang.org/x/crypto/ripemd160"
+	"golang.org/x/crypto/sha3"
+)
+
+// HashAlgo selects one of the supported hash functions
+type HashAlgo int
+
+const (
+	SHA256 HashAlgo = iota + 1
+	SHA512
+	Keccak256
+	RIPEMD160
+)
+
+// String returns the name of the hash algorithm
+func (h HashAlgo) String() string {
+	switch h {
+	case SHA256:
+		return "SHA256"
+	case SHA512:
+		return "SHA512"
+	case Keccak256:
+		return "KECCAK256"
+	case RIPEMD160:
+		return "RIPEMD160"
+	}
+	return fmt.Sprintf("HashAlgo(%d)", int(h))
+}
+
+// New returns a new hash.Hash for the algorithm.
+//
+// It panics on an unknown algorithm, as that is a programming error
+func (h HashAlgo) New() hash.Hash {
+	switch h {
+	case SHA256:
+		return sha256.New()
+	case SHA512:
+		return sha512.New()
+	case Keccak256:
+		// the original keccak, as used by ethereum, not sha3-256
+		return sha3.NewLegacyKeccak256()
+	case RIPEMD160:
+		return ripemd160.New()
+	}
+	panic(fmt.Sprintf("Unknown hash algorithm: %s", h))
+}
+
+// Hash returns the digest of the bytes under algo.
+//
+// It panics on an unknown algorithm, as that is a programming error
+func (b Bytes) Hash(algo HashAlgo) Bytes {
+	h := algo.New()
+	h.Write(b)
+	return Bytes(h.Sum(nil))
+}
+
+// Bucket maps the bytes to a bucket index in [0, n), for picking a shard by
+// key. It uses the 64 bit FNV-1a hash, which is fast and stable across
+// processes and versions, but not suitable for untrusted keys that may be
+// chosen to collide.
+//
+// It panics if n is not positive.
+func (b Bytes) Bucket(n int) int {
+	if n <= 0 {
+		panic(fmt.Sprintf("data: invalid bucket count %d", n))
+	}
+	h := fnv.New64a()
+	h.Write(b)
+	return int(h.Sum64() % uint64(n))
+}
+
+// KeyFingerprint returns the fingerprint of the raw key bytes in the style
+// of OpenSSH: the algorithm name, a colon and the digest as standard base64
+// without padding, like "SHA256:fiESGVun2XVk0NMsGLBNol3uSHrBN1ebHHwiGClot6U".
+//
+// For ssh keys, b must be the key blob (the base64 decoded middle part of
+// an authorized_keys line) to match the output of ssh-keygen -l.
+func (b Bytes) KeyFingerprint(algo HashAlgo) string {
+	return algo.String() + ":" + base64.RawStdEncoding.EncodeToString(b.Hash(algo))
+}
+
+// NamespacedID derives a deterministic id from the content b, as the
+// SHA256 of namespace, a zero byte and b. Different namespaces (eg. one per
+// kind of object) give unrelated ids for the same content, and the zero
+// byte keeps ("a", "bc") and ("ab", "c") apart.
+//
+// It panics if namespace contains a zero byte, which would make that
+// separation ambiguous again.
+func (b Bytes) NamespacedID(namespace string) Bytes {
+	if strings.IndexByte(namespace, 0) >= 0 {
+		panic(fmt.Sprintf("data: namespace contains a zero byte: %q", namespace))
+	}
+	h := sha256.New()
+	h.Write([]byte(namespace))
+	h.Write([]byte{0})
+	h.Write(b)
+	return h.Sum(nil)
+}
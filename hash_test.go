@@ -0,0 +1,160 @@
+package data_test
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"math/rand"
+	"strings"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHash(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		algo     data.HashAlgo
+		input    data.Bytes
+		expected string
+	}{
+		{data.SHA256, data.Bytes("abc"), "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{data.SHA256, data.Bytes{}, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{data.SHA512, data.Bytes("abc"), "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"},
+		{data.Keccak256, data.Bytes{}, "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+		{data.Keccak256, data.Bytes("abc"), "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"},
+		{data.RIPEMD160, data.Bytes("abc"), "8eb208f7e05d987a9b044a8e98c6b087f15a0bfc"},
+		{data.RIPEMD160, data.Bytes{}, "9c1185a5c5e9fc54612808977ee8f548b2258d31"},
+	}
+
+	for _, tc := range cases {
+		expected, err := hex.DecodeString(tc.expected)
+		assert.Nil(err, "%+v", err)
+		assert.Equal(data.Bytes(expected), tc.input.Hash(tc.algo), "%s %X", tc.algo, tc.input)
+	}
+
+	assert.Equal("SHA256", data.SHA256.String())
+	assert.Equal("HashAlgo(0)", data.HashAlgo(0).String())
+	// unknown algorithm is a programming error
+	assert.Panics(func() { data.Bytes("abc").Hash(data.HashAlgo(0)) })
+	assert.Panics(func() { data.Bytes("abc").Hash(data.HashAlgo(42)) })
+}
+
+func TestBucket(t *testing.T) {
+	assert := assert.New(t)
+
+	// stable values, these must never change
+	cases := []struct {
+		bytes    data.Bytes
+		n        int
+		expected int
+	}{
+		{nil, 1, 0},
+		{data.Bytes("foo"), 1, 0},
+		{nil, 1000, 37},
+		{data.Bytes("foo"), 1000, 407},
+		{data.Bytes{0x1a, 0x2b}, 7, 0},
+	}
+	for i, tc := range cases {
+		assert.Equal(tc.expected, tc.bytes.Bucket(tc.n), "%d", i)
+	}
+
+	// in range, deterministic, and evenly spread
+	r := rand.New(rand.NewSource(7))
+	for _, n := range []int{2, 10, 16, 100} {
+		const perBucket = 500
+		counts := make([]int, n)
+		for i := 0; i < n*perBucket; i++ {
+			b := make(data.Bytes, 1+r.Intn(40))
+			r.Read(b)
+			bucket := b.Bucket(n)
+			if !assert.True(bucket >= 0 && bucket < n, "%d: %d", n, bucket) {
+				return
+			}
+			assert.Equal(bucket, append(data.Bytes{}, b...).Bucket(n))
+			counts[bucket]++
+		}
+		for i, c := range counts {
+			assert.True(c > perBucket*7/10 && c < perBucket*13/10, "%d: bucket %d has %d", n, i, c)
+		}
+	}
+
+	assert.Panics(func() { data.Bytes("foo").Bucket(0) })
+	assert.Panics(func() { data.Bytes("foo").Bucket(-1) })
+}
+
+func TestKeyFingerprint(t *testing.T) {
+	assert := assert.New(t)
+
+	// an ed25519 key, and what ssh-keygen -l -E <algo> prints for it
+	blob, err := base64.StdEncoding.DecodeString(
+		"AAAAC3NzaC1lZDI1NTE5AAAAIPWJOop0hHY0MmYf5hWW5jb3xcPK50erPDndQGX2eYKg")
+	assert.Nil(err, "%+v", err)
+	key := data.Bytes(blob)
+	assert.Equal("SHA256:fiESGVun2XVk0NMsGLBNol3uSHrBN1ebHHwiGClot6U", key.KeyFingerprint(data.SHA256))
+	assert.Equal("SHA512:zXWSKtyNIwp97FUxyZVj80isaHJauHFj0wZiCoW1xh0qVz+PpuWvo0xUo2g9gDp6H2I9Ih4MBjvZnYl2hKO52w",
+		key.KeyFingerprint(data.SHA512))
+
+	// other algorithms get their own prefix
+	cases := []struct {
+		algo   data.HashAlgo
+		prefix string
+		size   int
+	}{
+		{data.SHA256, "SHA256:", 32},
+		{data.SHA512, "SHA512:", 64},
+		{data.Keccak256, "KECCAK256:", 32},
+		{data.RIPEMD160, "RIPEMD160:", 20},
+	}
+	for _, tc := range cases {
+		fp := key.KeyFingerprint(tc.algo)
+		if assert.True(strings.HasPrefix(fp, tc.prefix), fp) {
+			digest, err := base64.RawStdEncoding.DecodeString(fp[len(tc.prefix):])
+			assert.Nil(err, "%+v", err)
+			assert.Equal([]byte(key.Hash(tc.algo)), digest, fp)
+			assert.Equal(tc.size, len(digest), fp)
+		}
+	}
+}
+
+func TestNamespacedID(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		namespace string
+		content   data.Bytes
+		expected  string
+	}{
+		// sha256 of "blob\x00hello"
+		{"blob", data.Bytes("hello"), "B7DA690EBCE9312567657893E936FE6935D0A52372F2703F02616BBD53ECCB0C"},
+		// sha256 of "\x00"
+		{"", nil, "6E340B9CFFB37A989CA544E6BB780A2C78901D3FB33738768511A30617AFA01D"},
+		{"", data.Bytes{}, "6E340B9CFFB37A989CA544E6BB780A2C78901D3FB33738768511A30617AFA01D"},
+	}
+
+	for _, tc := range cases {
+		id := tc.content.NamespacedID(tc.namespace)
+		assert.Equal(tc.expected, strings.ToUpper(hex.EncodeToString(id)))
+		// deterministic
+		assert.Equal(id, tc.content.NamespacedID(tc.namespace))
+	}
+
+	// different namespaces give different ids
+	content := data.Bytes("same content")
+	seen := map[string]string{}
+	for _, ns := range []string{"", "blob", "tree", "Blob", "blob "} {
+		id := string(content.NamespacedID(ns))
+		if other, ok := seen[id]; ok {
+			t.Errorf("%q and %q give the same id", ns, other)
+		}
+		seen[id] = ns
+	}
+
+	// the separator keeps the boundary
+	assert.NotEqual(data.Bytes("bc").NamespacedID("a"), data.Bytes("c").NamespacedID("ab"))
+	assert.NotEqual(data.Bytes("").NamespacedID("ab"), data.Bytes("b").NamespacedID("a"))
+
+	// so the namespace must not contain it
+	assert.Panics(func() { data.Bytes("c").NamespacedID("a\x00b") })
+}
@@ -32,6 +32,67 @@ func FromJSON(d []byte, o interface{}) error {
 		json.Unmarshal(d, o))
 }
 
+var (
+	// ErrTooDeep is returned when a json document nests deeper than
+	// JSONLimits.MaxDepth
+	ErrTooDeep = errors.New("json document too deeply nested")
+	// ErrTooLarge is returned when a json document is bigger than
+	// JSONLimits.MaxBytes
+	ErrTooLarge = errors.New("json document too large")
+)
+
+// JSONLimits bounds the size of json documents from untrusted sources.
+// Zero values mean no limit.
+type JSONLimits struct {
+	// MaxDepth is the maximum nesting of arrays and objects
+	MaxDepth int
+	// MaxBytes is the maximum size of the document
+	MaxBytes int
+}
+
+// Check returns ErrTooLarge or ErrTooDeep if d exceeds the limits.
+//
+// It scans the document once without recursion or allocation, and does not
+// otherwise validate it. Use it before handing untrusted input to code that
+// recurses over the structure.
+func (l JSONLimits) Check(d []byte) error {
+	if l.MaxBytes > 0 && len(d) > l.MaxBytes {
+		return errors.Wrapf(ErrTooLarge, "%d > %d bytes", len(d), l.MaxBytes)
+	}
+	if l.MaxDepth <= 0 {
+		return nil
+	}
+	depth, inString, escaped := 0, false, false
+	for _, c := range d {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '[', c == '{':
+			depth++
+			if depth > l.MaxDepth {
+				return errors.Wrapf(ErrTooDeep, "more than %d levels", l.MaxDepth)
+			}
+		case c == ']', c == '}':
+			depth--
+		}
+	}
+	return nil
+}
+
+// FromJSONLimited is like FromJSON, but first checks d against limits
+func FromJSONLimited(d []byte, o interface{}, limits JSONLimits) error {
+	err := limits.Check(d)
+	if err != nil {
+		return err
+	}
+	return FromJSON(d, o)
+}
+
 // registerImplementation allows you to register multiple concrete types.
 //
 // Returns itself to allow calls to be chained
@@ -2,9 +2,11 @@ package data_test
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	data "github.com/neatio-net/data-go"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -55,3 +57,42 @@ func TestNestedJSON(t *testing.T) {
 		assert.Equal(tc.expected, parsed.Foo())
 	}
 }
+
+func TestJSONLimits(t *testing.T) {
+	assert := assert.New(t)
+
+	deep := strings.Repeat("[", 10000) + strings.Repeat("]", 10000)
+	cases := []struct {
+		doc    string
+		limits data.JSONLimits
+		err    error
+	}{
+		{`{"a": [1, 2, {"b": 3}]}`, data.JSONLimits{MaxDepth: 3, MaxBytes: 100}, nil},
+		{`{"a": [1, 2, {"b": 3}]}`, data.JSONLimits{MaxDepth: 2}, data.ErrTooDeep},
+		{`{"a": [1, 2, {"b": 3}]}`, data.JSONLimits{MaxBytes: 10}, data.ErrTooLarge},
+		// brackets in strings don't count
+		{`{"a": "[[[{{{\"[[["}`, data.JSONLimits{MaxDepth: 1}, nil},
+		{deep, data.JSONLimits{MaxDepth: 100}, data.ErrTooDeep},
+		{deep, data.JSONLimits{MaxBytes: 1000}, data.ErrTooLarge},
+		// no limits
+		{deep, data.JSONLimits{}, nil},
+	}
+
+	for i, tc := range cases {
+		err := tc.limits.Check([]byte(tc.doc))
+		if tc.err == nil {
+			assert.Nil(err, "%d: %+v", i, err)
+		} else {
+			assert.True(errors.Is(err, tc.err), "%d: %+v", i, err)
+		}
+	}
+
+	// and before parsing
+	var parsed interface{}
+	limits := data.JSONLimits{MaxDepth: 64, MaxBytes: 1 << 20}
+	err := data.FromJSONLimited([]byte(deep), &parsed, limits)
+	assert.True(errors.Is(err, data.ErrTooDeep), "%+v", err)
+	err = data.FromJSONLimited([]byte(`{"a": [1]}`), &parsed, limits)
+	assert.Nil(err, "%+v", err)
+	assert.Equal(map[string]interface{}{"a": []interface{}{1.0}}, parsed)
+}
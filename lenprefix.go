@@ -0,0 +1,116 @@
+package data
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// LengthPrefixedB64Encoder encodes bytes as padded url-safe base64 (like
+// B64Encoder) of the length as an unsigned varint, followed by the bytes.
+// Unmarshal checks that the length matches, so a truncated or tampered
+// value is an error.
+//
+// As every value carries its length, and its padding ends it on a block
+// boundary, several values can be concatenated in one string and split
+// again with SplitLengthPrefixedB64.
+var LengthPrefixedB64Encoder ByteEncoder = lengthPrefixedEncoder{}
+
+// lengthPrefixedEncoder implements ByteEncoder with a varint length prefix
+type lengthPrefixedEncoder struct{}
+
+func (e lengthPrefixedEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (_ lengthPrefixedEncoder) Unmarshal(dst *[]byte, src []byte) error {
+	s, err := ParseJSONString(src)
+	if err != nil {
+		return err
+	}
+	if s == "" {
+		*dst = []byte{}
+		return nil
+	}
+	res, err := decodeLengthPrefixed(s)
+	if err != nil {
+		return err
+	}
+	*dst = res
+	return nil
+}
+
+func (_ lengthPrefixedEncoder) Marshal(bytes []byte) ([]byte, error) {
+	return json.Marshal(base64.URLEncoding.EncodeToString(Bytes(bytes).MarshalWire()))
+}
+
+// decodeLengthPrefixed decodes one value, which must be all of text
+func decodeLengthPrefixed(text string) ([]byte, error) {
+	raw, err := base64.URLEncoding.DecodeString(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode base64")
+	}
+	size, n := binary.Uvarint(raw)
+	if n <= 0 {
+		return nil, errors.New("Invalid length prefix")
+	}
+	if size != uint64(len(raw)-n) {
+		return nil, errors.Errorf("Length prefix mismatch: expected %d bytes, got %d", size, len(raw)-n)
+	}
+	return raw[n:], nil
+}
+
+// SplitLengthPrefixedB64 decodes a json string of values encoded with
+// LengthPrefixedB64Encoder and concatenated, returning them in order.
+// "" (or null) holds no values.
+func SplitLengthPrefixedB64(src []byte) ([]Bytes, error) {
+	text, err := ParseJSONString(src)
+	if err != nil {
+		return nil, err
+	}
+	res := []Bytes{}
+	for len(text) > 0 {
+		size, err := lengthPrefixedSize(text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "value %d", len(res))
+		}
+		if size > len(text) {
+			return nil, errors.Errorf("Value %d is truncated: needs %d characters, have %d", len(res), size, len(text))
+		}
+		val, err := decodeLengthPrefixed(text[:size])
+		err = formatHook("lenprefix", size, val, err)
+		if err != nil {
+			return nil, errors.Wrapf(err, "value %d", len(res))
+		}
+		res = append(res, val)
+		text = text[size:]
+	}
+	return res, nil
+}
+
+// lengthPrefixedSize returns the number of characters of the value at the
+// start of text, reading only as many base64 blocks as the varint needs
+func lengthPrefixedSize(text string) (int, error) {
+	var raw []byte
+	var buf [3]byte
+	for i := 0; i+4 <= len(text) && i < 4*4; i += 4 {
+		n, err := base64.URLEncoding.Decode(buf[:], []byte(text[i:i+4]))
+		if err != nil {
+			return 0, errors.Wrap(err, "decode base64")
+		}
+		raw = append(raw, buf[:n]...)
+		size, vn := binary.Uvarint(raw)
+		if vn < 0 || vn == 0 && n < 3 {
+			break
+		}
+		if vn > 0 {
+			if size > uint64(len(text)) {
+				return 0, errors.Errorf("Length prefix %d exceeds input", size)
+			}
+			return base64.URLEncoding.EncodedLen(vn + int(size)), nil
+		}
+	}
+	return 0, errors.New("Invalid length prefix")
+}
@@ -0,0 +1,84 @@
+package data_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLengthPrefixedB64Encoder(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	enc := data.LengthPrefixedB64Encoder
+	large := data.Bytes(bytes.Repeat([]byte{0xab}, 200))
+	cases := []struct {
+		input    data.Bytes
+		expected string
+	}{
+		{data.Bytes{}, `"AA=="`},
+		{data.Bytes{0x1a, 0x2b}, `"Ahor"`},
+		{data.Bytes("D!.3s"), `"BUQhLjNz"`},
+		// two byte length
+		{large, `"yAGrq6urq6ur` + strings.Repeat("q6ur", 64) + `qw=="`},
+	}
+
+	var all []string
+	for _, tc := range cases {
+		out, err := enc.Marshal(tc.input)
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.expected, string(out))
+
+		var back []byte
+		err = enc.Unmarshal(&back, out)
+		if assert.Nil(err, "%s: %+v", out, err) {
+			assert.Equal([]byte(tc.input), back)
+		}
+		all = append(all, strings.Trim(string(out), `"`))
+	}
+
+	// they can be concatenated, and split again
+	joined, err := json.Marshal(strings.Join(all, ""))
+	require.Nil(err)
+	parts, err := data.SplitLengthPrefixedB64(joined)
+	if assert.Nil(err, "%+v", err) && assert.Len(parts, len(cases)) {
+		for i, tc := range cases {
+			assert.Equal(tc.input, parts[i], "%d", i)
+		}
+	}
+	// but not decoded as one
+	var back []byte
+	assert.NotNil(enc.Unmarshal(&back, joined))
+
+	parts, err = data.SplitLengthPrefixedB64([]byte(`""`))
+	assert.Nil(err)
+	assert.Empty(parts)
+
+	// a tampered length prefix doesn't match
+	for _, bad := range []string{
+		`"Axor"`, // says 3 bytes
+		`"ARor"`, // says 1 byte
+		`"Ahor` + `AA=="`,
+		`"yAGrq6ur"`,
+		`"gA=="`, // truncated varint
+		`"Ahor="`,
+		`12`,
+	} {
+		var back []byte
+		assert.NotNil(enc.Unmarshal(&back, []byte(bad)), bad)
+	}
+	for _, bad := range []string{
+		`"AhorAxor"`,
+		`"AhorBUQh"`, // second value is cut off
+		`"AhorAA="`,
+		`"yAGrq6ur"`,
+		`"gICAgICAgICAgICA"`,
+	} {
+		_, err := data.SplitLengthPrefixedB64([]byte(bad))
+		assert.NotNil(err, bad)
+	}
+}
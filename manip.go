@@ -0,0 +1,176 @@
+package data
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+)
+
+// PadLeft returns a copy of b, prefixed with as many fill bytes as needed
+// to make it n bytes long. If b is already at least n bytes, it returns an
+// unmodified copy.
+func (b Bytes) PadLeft(n int, fill byte) Bytes {
+	if len(b) >= n {
+		return append(Bytes{}, b...)
+	}
+	res := make(Bytes, n)
+	pad := n - len(b)
+	for i := 0; i < pad; i++ {
+		res[i] = fill
+	}
+	copy(res[pad:], b)
+	return res
+}
+
+// PadRight returns a copy of b, followed by as many fill bytes as needed
+// to make it n bytes long. If b is already at least n bytes, it returns an
+// unmodified copy.
+func (b Bytes) PadRight(n int, fill byte) Bytes {
+	if len(b) >= n {
+		return append(Bytes{}, b...)
+	}
+	res := make(Bytes, n)
+	copy(res, b)
+	for i := len(b); i < n; i++ {
+		res[i] = fill
+	}
+	return res
+}
+
+// Concat joins all parts into a newly allocated slice
+func Concat(parts ...Bytes) Bytes {
+	n := 0
+	for _, p := range parts {
+		n += len(p)
+	}
+	res := make(Bytes, 0, n)
+	for _, p := range parts {
+		res = append(res, p...)
+	}
+	return res
+}
+
+// LongestCommonPrefix returns a copy of the leading bytes that all items
+// share, eg. for compressing keys in a radix tree. With a single item that
+// is the whole item, and with no items (or no shared prefix) it is empty.
+func LongestCommonPrefix(items ...Bytes) Bytes {
+	if len(items) == 0 {
+		return Bytes{}
+	}
+	prefix := items[0]
+	for _, item := range items[1:] {
+		n := 0
+		for n < len(prefix) && n < len(item) && prefix[n] == item[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return append(Bytes{}, prefix...)
+}
+
+// Append returns a new slice with other after b. Unlike the builtin append,
+// it never modifies the backing array of b.
+func (b Bytes) Append(other Bytes) Bytes {
+	return Concat(b, other)
+}
+
+// Split slices b into all parts separated by sep, like bytes.Split, but
+// every part is a copy rather than a sub-slice of b.
+//
+// As with bytes.Split, an empty sep splits after each UTF-8 sequence, which
+// for binary data (that is mostly not valid UTF-8) means after every byte,
+// except for any valid multi-byte sequences.
+func (b Bytes) Split(sep Bytes) []Bytes {
+	return b.SplitN(sep, -1)
+}
+
+// SplitN is like Split, but returns at most n parts (the last one being
+// the unsplit remainder), like bytes.SplitN. n < 0 returns all parts, and
+// n == 0 returns nil.
+func (b Bytes) SplitN(sep Bytes, n int) []Bytes {
+	parts := bytes.SplitN(b, sep, n)
+	if parts == nil {
+		return nil
+	}
+	res := make([]Bytes, len(parts))
+	for i, p := range parts {
+		res[i] = append(Bytes{}, p...)
+	}
+	return res
+}
+
+// Reverse returns a copy of b with the bytes in reverse order, eg. to
+// switch between little and big endian. nil returns nil.
+func (b Bytes) Reverse() Bytes {
+	if b == nil {
+		return nil
+	}
+	res := make(Bytes, len(b))
+	for i, c := range b {
+		res[len(b)-1-i] = c
+	}
+	return res
+}
+
+// RotateLeft returns a copy of b with the bytes shifted n positions to the
+// left, the first n wrapping around to the end: {1, 2, 3} rotated by 1 is
+// {2, 3, 1}. n is taken modulo len(b), so rotating by len(b) (or any
+// multiple) gives an unmodified copy, and a negative n rotates right.
+// nil returns nil.
+func (b Bytes) RotateLeft(n int) Bytes {
+	if b == nil {
+		return nil
+	}
+	res := make(Bytes, 0, len(b))
+	if len(b) == 0 {
+		return res
+	}
+	n %= len(b)
+	if n < 0 {
+		n += len(b)
+	}
+	res = append(res, b[n:]...)
+	return append(res, b[:n]...)
+}
+
+// RotateRight is the inverse of RotateLeft: {1, 2, 3} rotated by 1 is
+// {3, 1, 2}. The same modulo rules apply.
+func (b Bytes) RotateRight(n int) Bytes {
+	// negate after the modulo, so this can't overflow
+	if len(b) > 0 {
+		n %= len(b)
+	}
+	return b.RotateLeft(-n)
+}
+
+// Mask returns a copy of b with the bytes in [start, end) replaced by fill,
+// eg. to redact part of a value for logging. The range must be within b,
+// an empty range returns an unmodified copy.
+func (b Bytes) Mask(start, end int, fill byte) (Bytes, error) {
+	if start < 0 || end < start || end > len(b) {
+		return nil, errors.Errorf("Invalid mask range [%d:%d] for %d bytes", start, end, len(b))
+	}
+	res := append(Bytes{}, b...)
+	for i := start; i < end; i++ {
+		res[i] = fill
+	}
+	return res, nil
+}
+
+// Zero overwrites b with zeros in place, to scrub secrets like keys once
+// they are no longer needed:
+//
+//	key := loadKey()
+//	defer key.Zero()
+//
+// This is best effort. Only the memory b points to is cleared, not any
+// copies: those made by the methods here (which mostly return copies), by
+// Unmarshal and MarshalJSON, by converting b to a string, or by append
+// when it grows a slice (and the runtime when it grows a goroutine stack).
+// Slices sharing the backing array with b do see the zeros. nil and empty
+// values are left alone.
+func (b Bytes) Zero() {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,297 @@
+package data_test
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPad(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		bytes       data.Bytes
+		n           int
+		fill        byte
+		left, right data.Bytes
+	}{
+		// under-length
+		{data.Bytes{0x12, 0x34}, 4, 0x00, data.Bytes{0, 0, 0x12, 0x34}, data.Bytes{0x12, 0x34, 0, 0}},
+		{data.Bytes{0x12}, 3, 0xff, data.Bytes{0xff, 0xff, 0x12}, data.Bytes{0x12, 0xff, 0xff}},
+		{data.Bytes{}, 2, 0x20, data.Bytes{0x20, 0x20}, data.Bytes{0x20, 0x20}},
+		{nil, 1, 0x00, data.Bytes{0}, data.Bytes{0}},
+		// exact length
+		{data.Bytes{0x12, 0x34}, 2, 0x00, data.Bytes{0x12, 0x34}, data.Bytes{0x12, 0x34}},
+		// over-length
+		{data.Bytes{0x12, 0x34, 0x56}, 2, 0x00, data.Bytes{0x12, 0x34, 0x56}, data.Bytes{0x12, 0x34, 0x56}},
+		{data.Bytes{0x12}, -1, 0x00, data.Bytes{0x12}, data.Bytes{0x12}},
+	}
+
+	for i, tc := range cases {
+		orig := append(data.Bytes(nil), tc.bytes...)
+		left, right := tc.bytes.PadLeft(tc.n, tc.fill), tc.bytes.PadRight(tc.n, tc.fill)
+		assert.Equal(tc.left, left, "%d", i)
+		assert.Equal(tc.right, right, "%d", i)
+
+		// results never alias the source
+		if len(left) > 0 && len(tc.bytes) > 0 {
+			left[len(left)-1]++
+			right[0]++
+			assert.Equal([]byte(orig), []byte(tc.bytes), "%d", i)
+		}
+	}
+}
+
+func TestConcat(t *testing.T) {
+	assert := assert.New(t)
+
+	a, b, c := data.Bytes{0x01, 0x02}, data.Bytes{}, data.Bytes{0x03}
+	cases := []struct {
+		parts    []data.Bytes
+		expected data.Bytes
+	}{
+		{nil, data.Bytes{}},
+		{[]data.Bytes{a}, data.Bytes{0x01, 0x02}},
+		{[]data.Bytes{a, b, c}, data.Bytes{0x01, 0x02, 0x03}},
+		{[]data.Bytes{c, nil, a, c}, data.Bytes{0x03, 0x01, 0x02, 0x03}},
+	}
+
+	for i, tc := range cases {
+		res := data.Concat(tc.parts...)
+		assert.Equal(tc.expected, res, "%d", i)
+		// no aliasing with the inputs
+		if len(res) > 0 {
+			res[0] = 0xff
+			assert.Equal(data.Bytes{0x01, 0x02}, a, "%d", i)
+			assert.Equal(data.Bytes{0x03}, c, "%d", i)
+		}
+	}
+
+	// append doesn't modify the receiver, even with spare capacity
+	buf := make(data.Bytes, 2, 10)
+	buf[0], buf[1] = 0x0a, 0x0b
+	res := buf.Append(data.Bytes{0x0c})
+	assert.Equal(data.Bytes{0x0a, 0x0b, 0x0c}, res)
+	assert.Equal(data.Bytes{0x0a, 0x0b, 0x00}, buf[:3])
+	res[0] = 0xff
+	assert.Equal(data.Bytes{0x0a, 0x0b}, buf)
+	assert.Equal(data.Bytes{}, data.Bytes(nil).Append(nil))
+}
+
+func TestLongestCommonPrefix(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		items    []data.Bytes
+		expected data.Bytes
+	}{
+		{nil, data.Bytes{}},
+		{[]data.Bytes{{0x01, 0x02}}, data.Bytes{0x01, 0x02}},
+		{[]data.Bytes{nil}, data.Bytes{}},
+		// full match
+		{[]data.Bytes{{0x01, 0x02}, {0x01, 0x02}, {0x01, 0x02}}, data.Bytes{0x01, 0x02}},
+		// one is a prefix of the others
+		{[]data.Bytes{{0x01, 0x02, 0x03}, {0x01}, {0x01, 0x02}}, data.Bytes{0x01}},
+		{[]data.Bytes{{0x01, 0x02, 0x03, 0x04}, {0x01, 0x02, 0x03, 0x05}, {0x01, 0x02, 0x07}}, data.Bytes{0x01, 0x02}},
+		// nothing in common
+		{[]data.Bytes{{0x01, 0x02}, {0x02, 0x02}}, data.Bytes{}},
+		{[]data.Bytes{{0x01, 0x02}, {0x01, 0x02}, {}}, data.Bytes{}},
+		{[]data.Bytes{{0x01}, {0x01}, {0x01}, {0x09}}, data.Bytes{}},
+	}
+
+	for i, tc := range cases {
+		res := data.LongestCommonPrefix(tc.items...)
+		assert.Equal(tc.expected, res, "%d", i)
+		// no aliasing with the inputs
+		if len(res) > 0 {
+			res[0] = 0xff
+			assert.Equal(byte(0x01), tc.items[0][0], "%d", i)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	assert := assert.New(t)
+
+	rec := data.Bytes("a|bc||d")
+	sep := data.Bytes("|")
+	cases := []struct {
+		bytes    data.Bytes
+		sep      data.Bytes
+		n        int
+		expected []data.Bytes
+	}{
+		// not present
+		{data.Bytes("abc"), sep, -1, []data.Bytes{data.Bytes("abc")}},
+		// multiple occurrences, with empty parts
+		{rec, sep, -1, []data.Bytes{data.Bytes("a"), data.Bytes("bc"), {}, data.Bytes("d")}},
+		{data.Bytes("a::b::"), data.Bytes("::"), -1, []data.Bytes{data.Bytes("a"), data.Bytes("b"), {}}},
+		// empty separator splits bytes that are not valid utf-8 one by one
+		{data.Bytes{0x01, 0xff, 0x02}, data.Bytes{}, -1, []data.Bytes{{0x01}, {0xff}, {0x02}}},
+		{data.Bytes{}, sep, -1, []data.Bytes{{}}},
+		// limits
+		{rec, sep, 2, []data.Bytes{data.Bytes("a"), data.Bytes("bc||d")}},
+		{rec, sep, 1, []data.Bytes{rec}},
+		{rec, sep, 0, nil},
+		{rec, sep, 10, []data.Bytes{data.Bytes("a"), data.Bytes("bc"), {}, data.Bytes("d")}},
+	}
+
+	for i, tc := range cases {
+		res := tc.bytes.SplitN(tc.sep, tc.n)
+		assert.Equal(tc.expected, res, "%d", i)
+		if tc.n < 0 {
+			assert.Equal(tc.expected, tc.bytes.Split(tc.sep), "%d", i)
+		}
+	}
+
+	// parts are copies
+	parts := rec.Split(sep)
+	parts[0][0] = 'z'
+	parts[3] = append(parts[3], 'x')
+	assert.Equal(data.Bytes("a|bc||d"), rec)
+}
+
+func TestReverse(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		bytes, expected data.Bytes
+	}{
+		{data.Bytes{0x01, 0x02, 0x03, 0x04}, data.Bytes{0x04, 0x03, 0x02, 0x01}},
+		{data.Bytes{0x01, 0x02, 0x03}, data.Bytes{0x03, 0x02, 0x01}},
+		{data.Bytes{0x01}, data.Bytes{0x01}},
+		{data.Bytes{}, data.Bytes{}},
+		{nil, nil},
+	}
+
+	for i, tc := range cases {
+		orig := tc.bytes.Reverse().Reverse()
+		res := tc.bytes.Reverse()
+		assert.Equal(tc.expected, res, "%d", i)
+		if len(res) > 0 {
+			res[0]++
+		}
+		// doesn't touch the receiver
+		assert.Equal(orig, tc.bytes, "%d", i)
+	}
+
+	// switches endianness
+	le, be := make(data.Bytes, 8), make(data.Bytes, 8)
+	binary.LittleEndian.PutUint64(le, 0x0102030405060708)
+	binary.BigEndian.PutUint64(be, 0x0102030405060708)
+	assert.Equal(be, le.Reverse())
+	assert.Equal(uint64(0x0102030405060708), binary.BigEndian.Uint64(le.Reverse()))
+}
+
+func TestRotate(t *testing.T) {
+	assert := assert.New(t)
+
+	b := data.Bytes{0x01, 0x02, 0x03, 0x04}
+	cases := []struct {
+		bytes       data.Bytes
+		n           int
+		left, right data.Bytes
+	}{
+		{b, 1, data.Bytes{0x02, 0x03, 0x04, 0x01}, data.Bytes{0x04, 0x01, 0x02, 0x03}},
+		{b, 3, data.Bytes{0x04, 0x01, 0x02, 0x03}, data.Bytes{0x02, 0x03, 0x04, 0x01}},
+		// by 0 and len
+		{b, 0, b, b},
+		{b, 4, b, b},
+		{b, 8, b, b},
+		// larger than len
+		{b, 5, data.Bytes{0x02, 0x03, 0x04, 0x01}, data.Bytes{0x04, 0x01, 0x02, 0x03}},
+		{b, 1002, data.Bytes{0x03, 0x04, 0x01, 0x02}, data.Bytes{0x03, 0x04, 0x01, 0x02}},
+		// negative goes the other way
+		{b, -1, data.Bytes{0x04, 0x01, 0x02, 0x03}, data.Bytes{0x02, 0x03, 0x04, 0x01}},
+		{b, math.MinInt32, b, b},
+		{data.Bytes{0x01}, 7, data.Bytes{0x01}, data.Bytes{0x01}},
+		// empty
+		{data.Bytes{}, 3, data.Bytes{}, data.Bytes{}},
+		{nil, 3, nil, nil},
+	}
+
+	for i, tc := range cases {
+		orig := append(data.Bytes(nil), tc.bytes...)
+		left, right := tc.bytes.RotateLeft(tc.n), tc.bytes.RotateRight(tc.n)
+		assert.Equal(tc.left, left, "%d", i)
+		assert.Equal(tc.right, right, "%d", i)
+		assert.Equal(tc.bytes == nil, left == nil, "%d", i)
+		assert.Equal(tc.bytes, left.RotateRight(tc.n), "%d", i)
+		// always a copy
+		if len(left) > 0 {
+			left[0]++
+			right[0]++
+		}
+		assert.Equal(orig, append(data.Bytes(nil), tc.bytes...), "%d", i)
+	}
+}
+
+func TestMask(t *testing.T) {
+	assert := assert.New(t)
+
+	orig := data.Bytes{0x01, 0x02, 0x03, 0x04, 0x05}
+	cases := []struct {
+		start, end int
+		fill       byte
+		expected   data.Bytes
+	}{
+		{1, 4, 0x00, data.Bytes{0x01, 0x00, 0x00, 0x00, 0x05}},
+		{0, 5, 0xff, data.Bytes{0xff, 0xff, 0xff, 0xff, 0xff}},
+		{3, 5, 'x', data.Bytes{0x01, 0x02, 0x03, 'x', 'x'}},
+		// empty range
+		{2, 2, 0x00, orig},
+		{5, 5, 0x00, orig},
+		// out of bounds
+		{-1, 2, 0x00, nil},
+		{3, 2, 0x00, nil},
+		{0, 6, 0x00, nil},
+		{6, 6, 0x00, nil},
+	}
+
+	for i, tc := range cases {
+		b := append(data.Bytes{}, orig...)
+		res, err := b.Mask(tc.start, tc.end, tc.fill)
+		if tc.expected == nil {
+			assert.NotNil(err, "%d", i)
+		} else if assert.Nil(err, "%d: %+v", i, err) {
+			assert.Equal(tc.expected, res, "%d", i)
+			// always a copy
+			res[0]++
+		}
+		// doesn't touch the receiver
+		assert.Equal(orig, b, "%d", i)
+	}
+
+	res, err := data.Bytes{}.Mask(0, 0, 0x00)
+	assert.Nil(err, "%+v", err)
+	assert.Equal(data.Bytes{}, res)
+}
+
+func TestZero(t *testing.T) {
+	assert := assert.New(t)
+
+	key := data.Bytes{0xde, 0xad, 0xbe, 0xef, 0x01}
+	alias := key[1:3]
+	key.Zero()
+	assert.Equal(data.Bytes{0, 0, 0, 0, 0}, key)
+	assert.Len(key, 5)
+	// the backing array is cleared, so slices of it see the zeros
+	assert.Equal(data.Bytes{0, 0}, alias)
+
+	// only the given part
+	buf := data.Bytes{0x01, 0x02, 0x03, 0x04}
+	buf[1:3].Zero()
+	assert.Equal(data.Bytes{0x01, 0, 0, 0x04}, buf)
+
+	// copies are not affected
+	orig := data.Bytes{0x1a, 0x2b}
+	cp := orig.Reverse()
+	orig.Zero()
+	assert.Equal(data.Bytes{0x2b, 0x1a}, cp)
+
+	assert.NotPanics(func() { data.Bytes(nil).Zero() })
+	assert.NotPanics(func() { data.Bytes{}.Zero() })
+}
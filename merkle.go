@@ -0,0 +1,36 @@
+package data
+
+// MerkleRoot computes the root of a binary merkle tree over leaves, as
+// defined in RFC 6962 (certificate transparency), using algo:
+//
+//	root([])        = H()
+//	root([d])       = H(0x00 || d)
+//	root(d[0:n])    = H(0x01 || root(d[0:k]) || root(d[k:n]))
+//
+// where k is the largest power of two smaller than n. The distinct leaf and
+// node prefixes prevent a leaf from being passed off as an inner node.
+//
+// Odd counts are never padded by duplicating the last element (as bitcoin
+// does). Instead, the tree is split unevenly, so the last leaf is hashed up
+// on its own until it meets a subtree of equal height. This means the leaves
+// [a, b, c] and [a, b, c, c] have different roots.
+func MerkleRoot(leaves []Bytes, algo HashAlgo) Bytes {
+	if len(leaves) == 0 {
+		return Bytes(algo.New().Sum(nil))
+	}
+	if len(leaves) == 1 {
+		h := algo.New()
+		h.Write([]byte{0x00})
+		h.Write(leaves[0])
+		return Bytes(h.Sum(nil))
+	}
+	k := 1
+	for k*2 < len(leaves) {
+		k *= 2
+	}
+	h := algo.New()
+	h.Write([]byte{0x01})
+	h.Write(MerkleRoot(leaves[:k], algo))
+	h.Write(MerkleRoot(leaves[k:], algo))
+	return Bytes(h.Sum(nil))
+}
@@ -0,0 +1,55 @@
+package data_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerkleRoot(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	// leaves and roots from the certificate transparency test vectors
+	var leaves []data.Bytes
+	for _, l := range []string{"", "00", "10", "2021", "3031", "40414243",
+		"5051525354555657", "606162636465666768696a6b6c6d6e6f"} {
+		b, err := hex.DecodeString(l)
+		require.Nil(err, "%+v", err)
+		leaves = append(leaves, data.Bytes(b))
+	}
+	roots := []string{
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+		"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+		"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+		"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+		"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+		"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+		"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+	}
+
+	for n, expected := range roots {
+		root := data.MerkleRoot(leaves[:n], data.SHA256)
+		assert.Equal(expected, hex.EncodeToString(root), "%d leaves", n)
+		// deterministic
+		assert.Equal(root, data.MerkleRoot(leaves[:n], data.SHA256), "%d leaves", n)
+	}
+
+	// other hashes
+	root := data.MerkleRoot([]data.Bytes{data.Bytes("a"), data.Bytes("b")}, data.SHA512)
+	assert.Equal(64, len(root))
+	assert.Equal("4b46df98b7104978e58a14ed3d5febb8", hex.EncodeToString(root[:16]))
+
+	// no duplication of the last leaf
+	abc := []data.Bytes{data.Bytes("a"), data.Bytes("b"), data.Bytes("c")}
+	abcc := append(abc, data.Bytes("c"))
+	assert.NotEqual(data.MerkleRoot(abc, data.SHA256), data.MerkleRoot(abcc, data.SHA256))
+
+	// order matters
+	ba := []data.Bytes{data.Bytes("b"), data.Bytes("a")}
+	assert.NotEqual(data.MerkleRoot(abc[:2], data.SHA256), data.MerkleRoot(ba, data.SHA256))
+}
@@ -0,0 +1,415 @@
+package data
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// WithTag returns a ByteEncoder that prepends tag to the bytes before
+// encoding them with inner, and requires (and strips) it when decoding.
+//
+// The tag is part of the decoded bytes, not the text, so this is useful
+// for domain separation, eg. when the same bytes are also used as input
+// to a hash.
+func WithTag(tag []byte, inner ByteEncoder) ByteEncoder {
+	return tagEncoder{
+		tag:   append([]byte(nil), tag...),
+		inner: inner,
+	}
+}
+
+// tagEncoder implements ByteEncoder, adding a fixed prefix to the raw bytes
+type tagEncoder struct {
+	tag   []byte
+	inner ByteEncoder
+}
+
+func (e tagEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (e tagEncoder) Unmarshal(dst *[]byte, src []byte) error {
+	var raw []byte
+	err := e.inner.Unmarshal(&raw, src)
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(raw, e.tag) {
+		return errors.Errorf("Missing tag %X", e.tag)
+	}
+	*dst = raw[len(e.tag):]
+	return nil
+}
+
+func (e tagEncoder) Marshal(bytes []byte) ([]byte, error) {
+	raw := make([]byte, 0, len(e.tag)+len(bytes))
+	raw = append(raw, e.tag...)
+	raw = append(raw, bytes...)
+	return e.inner.Marshal(raw)
+}
+
+// WithAESGCM returns a ByteEncoder that encrypts the bytes with AES-GCM
+// under key before encoding them with inner, and decrypts them again when
+// decoding. A random nonce is generated for every Marshal, and prepended to
+// the ciphertext.
+//
+// key must be 16, 24 or 32 bytes, to select AES-128, AES-192 or AES-256.
+//
+// This only protects the bytes in the serialized form. Generating, storing
+// and rotating the key is up to the caller, and as the nonce is random, do
+// not encrypt more than 2^32 values with the same key.
+func WithAESGCM(key []byte, inner ByteEncoder) (ByteEncoder, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return aesGCMEncoder{aead: aead, inner: inner}, nil
+}
+
+// aesGCMEncoder implements ByteEncoder, encrypting the raw bytes
+type aesGCMEncoder struct {
+	aead  cipher.AEAD
+	inner ByteEncoder
+}
+
+func (e aesGCMEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (e aesGCMEncoder) Unmarshal(dst *[]byte, src []byte) error {
+	var raw []byte
+	err := e.inner.Unmarshal(&raw, src)
+	if err != nil {
+		return err
+	}
+	size := e.aead.NonceSize()
+	if len(raw) < size+e.aead.Overhead() {
+		return errors.Errorf("Ciphertext too short: %d bytes", len(raw))
+	}
+	// decrypt in place
+	ciphertext := raw[size:]
+	plain, err := e.aead.Open(ciphertext[:0], raw[:size], ciphertext, nil)
+	if err != nil {
+		return errors.Wrap(err, "decrypt")
+	}
+	*dst = plain
+	return nil
+}
+
+func (e aesGCMEncoder) Marshal(bytes []byte) ([]byte, error) {
+	nonce := make([]byte, e.aead.NonceSize(), e.aead.NonceSize()+len(bytes)+e.aead.Overhead())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, errors.Wrap(err, "generate nonce")
+	}
+	return e.inner.Marshal(e.aead.Seal(nonce, nonce, bytes, nil))
+}
+
+// WithLenientQuotes returns a ByteEncoder that also accepts single-quoted
+// strings like '1a2b' on Unmarshal, as some yaml to json bridges produce.
+// Everything else (and Marshal) is passed on to inner unchanged.
+//
+// Note this only helps when calling Unmarshal directly, as encoding/json
+// rejects single quotes before any UnmarshalJSON is called.
+func WithLenientQuotes(inner ByteEncoder) ByteEncoder {
+	return lenientQuotesEncoder{inner}
+}
+
+// lenientQuotesEncoder implements ByteEncoder, rewriting '...' to "..."
+type lenientQuotesEncoder struct {
+	inner ByteEncoder
+}
+
+func (e lenientQuotesEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (e lenientQuotesEncoder) Unmarshal(dst *[]byte, src []byte) error {
+	trimmed := bytes.TrimSpace(src)
+	if len(trimmed) >= 2 && trimmed[0] == '\'' && trimmed[len(trimmed)-1] == '\'' {
+		quoted, err := json.Marshal(string(trimmed[1 : len(trimmed)-1]))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		src = quoted
+	}
+	return e.inner.Unmarshal(dst, src)
+}
+
+func (e lenientQuotesEncoder) Marshal(bytes []byte) ([]byte, error) {
+	return e.inner.Marshal(bytes)
+}
+
+// WithBytesObject returns a ByteEncoder that also accepts the value wrapped
+// in an object, like {"bytes": "1a2b"}, on Unmarshal, as some gRPC gateways
+// produce. The "bytes" value is decoded by inner. Plain strings (and
+// Marshal) are passed on to inner unchanged.
+func WithBytesObject(inner ByteEncoder) ByteEncoder {
+	return bytesObjectEncoder{inner}
+}
+
+// bytesObjectEncoder implements ByteEncoder, unwrapping {"bytes": ...}
+type bytesObjectEncoder struct {
+	inner ByteEncoder
+}
+
+func (e bytesObjectEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (e bytesObjectEncoder) Unmarshal(dst *[]byte, src []byte) error {
+	if trimmed := bytes.TrimSpace(src); len(trimmed) > 0 && trimmed[0] == '{' {
+		var obj struct {
+			Bytes *json.RawMessage `json:"bytes"`
+		}
+		err := json.Unmarshal(trimmed, &obj)
+		if err != nil {
+			return errors.Wrap(err, "parse bytes object")
+		}
+		if obj.Bytes == nil {
+			return errors.New("Missing \"bytes\" key in object")
+		}
+		src = *obj.Bytes
+	}
+	return e.inner.Unmarshal(dst, src)
+}
+
+func (e bytesObjectEncoder) Marshal(bytes []byte) ([]byte, error) {
+	return e.inner.Marshal(bytes)
+}
+
+// WithCopyOutput returns a ByteEncoder that copies the result of
+// inner.Unmarshal, for third party encoders that may return slices of
+// their input. Marshal is passed on to inner unchanged.
+func WithCopyOutput(inner ByteEncoder) ByteEncoder {
+	return copyOutputEncoder{inner}
+}
+
+// copyOutputEncoder implements ByteEncoder, never aliasing src
+type copyOutputEncoder struct {
+	inner ByteEncoder
+}
+
+func (e copyOutputEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (e copyOutputEncoder) Unmarshal(dst *[]byte, src []byte) error {
+	var res []byte
+	err := e.inner.Unmarshal(&res, src)
+	if err != nil {
+		return err
+	}
+	if res != nil {
+		res = append(make([]byte, 0, len(res)), res...)
+	}
+	*dst = res
+	return nil
+}
+
+func (e copyOutputEncoder) Marshal(bytes []byte) ([]byte, error) {
+	return e.inner.Marshal(bytes)
+}
+
+// NewFallbackEncoder returns a ByteEncoder that marshals with primary, and
+// unmarshals with the first of primary and fallbacks (in order) that
+// succeeds. If all fail, the error from primary is returned, and dst is
+// left untouched.
+//
+// This is meant for migrating stored data from one encoding to another,
+// when the candidates are known. Be careful with encodings that may both
+// accept the same text (like hex and base64 for "1a2b"), the first wins.
+func NewFallbackEncoder(primary ByteEncoder, fallbacks ...ByteEncoder) ByteEncoder {
+	return fallbackEncoder{
+		primary:   primary,
+		fallbacks: append([]ByteEncoder(nil), fallbacks...),
+	}
+}
+
+// fallbackEncoder implements ByteEncoder, trying several decoders
+type fallbackEncoder struct {
+	primary   ByteEncoder
+	fallbacks []ByteEncoder
+}
+
+func (e fallbackEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (e fallbackEncoder) Unmarshal(dst *[]byte, src []byte) error {
+	var res []byte
+	err := e.primary.Unmarshal(&res, src)
+	if err == nil {
+		*dst = res
+		return nil
+	}
+	for _, enc := range e.fallbacks {
+		res = nil
+		if enc.Unmarshal(&res, src) == nil {
+			*dst = res
+			return nil
+		}
+	}
+	return err
+}
+
+func (e fallbackEncoder) Marshal(bytes []byte) ([]byte, error) {
+	return e.primary.Marshal(bytes)
+}
+
+// TrimTrailingNull returns a ByteEncoder that removes a single trailing
+// zero byte after decoding with inner, as C strings carry. Values without
+// one are passed on unchanged, and so is Marshal.
+func TrimTrailingNull(inner ByteEncoder) ByteEncoder {
+	return trimNullEncoder{inner: inner}
+}
+
+// TrimAllTrailingNulls is like TrimTrailingNull, but removes all trailing
+// zero bytes, as found in fixed size C buffers.
+func TrimAllTrailingNulls(inner ByteEncoder) ByteEncoder {
+	return trimNullEncoder{inner: inner, all: true}
+}
+
+// trimNullEncoder implements ByteEncoder, stripping trailing zero bytes
+type trimNullEncoder struct {
+	inner ByteEncoder
+	all   bool
+}
+
+func (e trimNullEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (e trimNullEncoder) Unmarshal(dst *[]byte, src []byte) error {
+	var res []byte
+	err := e.inner.Unmarshal(&res, src)
+	if err != nil {
+		return err
+	}
+	if e.all {
+		res = bytes.TrimRight(res, "\x00")
+	} else if len(res) > 0 && res[len(res)-1] == 0 {
+		res = res[:len(res)-1]
+	}
+	*dst = res
+	return nil
+}
+
+func (e trimNullEncoder) Marshal(bytes []byte) ([]byte, error) {
+	return e.inner.Marshal(bytes)
+}
+
+// WithCRC32 returns a ByteEncoder that appends the CRC-32 (IEEE) checksum
+// of the bytes, as 4 bytes big-endian, before encoding them with inner. It
+// verifies and strips it again when decoding, so accidental corruption of
+// stored values is detected.
+//
+// This only protects against accidents, not tampering, see WithAESGCM.
+func WithCRC32(inner ByteEncoder) ByteEncoder {
+	return crc32Encoder{inner}
+}
+
+// crc32Encoder implements ByteEncoder, adding a checksum to the raw bytes
+type crc32Encoder struct {
+	inner ByteEncoder
+}
+
+func (e crc32Encoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (e crc32Encoder) Unmarshal(dst *[]byte, src []byte) error {
+	var raw []byte
+	err := e.inner.Unmarshal(&raw, src)
+	if err != nil {
+		return err
+	}
+	if len(raw) < crc32.Size {
+		return errors.Errorf("Too short for a checksum: %d bytes", len(raw))
+	}
+	payload, sum := raw[:len(raw)-crc32.Size], raw[len(raw)-crc32.Size:]
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(sum) {
+		return errors.New("Checksum mismatch")
+	}
+	*dst = payload
+	return nil
+}
+
+func (e crc32Encoder) Marshal(bytes []byte) ([]byte, error) {
+	raw := make([]byte, len(bytes), len(bytes)+crc32.Size)
+	copy(raw, bytes)
+	raw = raw[:len(bytes)+crc32.Size]
+	binary.BigEndian.PutUint32(raw[len(bytes):], crc32.ChecksumIEEE(bytes))
+	return e.inner.Marshal(raw)
+}
+
+// WithAutoGunzip returns a ByteEncoder for fields that are only sometimes
+// gzip compressed. After decoding with inner, values that start with the
+// gzip magic bytes (1F 8B) are decompressed, and all others are passed on
+// as they are. Marshal is passed on to inner unchanged, so compress values
+// before marshaling them if they should be compressed.
+//
+// A corrupt or truncated gzip stream is an error, and so is a decompressed
+// value over maxSize bytes, to guard against gzip bombs. Raw values that
+// happen to start with 1F 8B can't be used with this.
+//
+// It panics if maxSize is negative.
+func WithAutoGunzip(inner ByteEncoder, maxSize int) ByteEncoder {
+	if maxSize < 0 {
+		panic(fmt.Sprintf("data: invalid gunzip size %d", maxSize))
+	}
+	return gunzipEncoder{inner: inner, max: maxSize}
+}
+
+// gunzipEncoder implements ByteEncoder, decompressing gzipped values
+type gunzipEncoder struct {
+	inner ByteEncoder
+	max   int
+}
+
+func (e gunzipEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (e gunzipEncoder) Unmarshal(dst *[]byte, src []byte) error {
+	var raw []byte
+	err := e.inner.Unmarshal(&raw, src)
+	if err != nil {
+		return err
+	}
+	if len(raw) < 2 || raw[0] != 0x1f || raw[1] != 0x8b {
+		*dst = raw
+		return nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return errors.Wrap(err, "gunzip")
+	}
+	res, err := ioutil.ReadAll(limitOverMax(zr, e.max))
+	if err != nil {
+		return errors.Wrap(err, "gunzip")
+	}
+	if len(res) > e.max {
+		return errors.Errorf("Decompressed value exceeds %d bytes", e.max)
+	}
+	*dst = res
+	return nil
+}
+
+func (e gunzipEncoder) Marshal(bytes []byte) ([]byte, error) {
+	return e.inner.Marshal(bytes)
+}
@@ -0,0 +1,492 @@
+package data_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTag(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	tagged := data.WithTag([]byte("tx"), data.HexEncoder)
+	nested := data.WithTag([]byte{0x01}, data.WithTag([]byte{0x02}, data.HexEncoder))
+	cases := []struct {
+		encoder  data.ByteEncoder
+		input    []byte
+		expected string
+	}{
+		{tagged, []byte{0xab, 0xcd}, `"7478ABCD"`},
+		{tagged, []byte{}, `"7478"`},
+		// inner tag comes first in the encoded bytes
+		{nested, []byte{0xff}, `"0201FF"`},
+	}
+
+	for i, tc := range cases {
+		enc, err := tc.encoder.Marshal(tc.input)
+		require.Nil(err, "%d: %+v", i, err)
+		assert.Equal(tc.expected, string(enc), "%d", i)
+
+		var output []byte
+		err = tc.encoder.Unmarshal(&output, enc)
+		require.Nil(err, "%d: %+v", i, err)
+		assert.Equal(tc.input, output, "%d", i)
+	}
+
+	// missing or wrong tags
+	errs := []struct {
+		encoder data.ByteEncoder
+		input   string
+	}{
+		{tagged, `"ABCD"`},
+		{tagged, `"74"`},
+		{tagged, `""`},
+		{nested, `"0102FF"`},
+		{nested, `"02FF"`},
+		// errors from the inner encoder
+		{tagged, `"7478zz"`},
+	}
+	for _, tc := range errs {
+		output := []byte("keep")
+		err := tc.encoder.Unmarshal(&output, []byte(tc.input))
+		assert.NotNil(err, tc.input)
+		assert.Equal([]byte("keep"), output, tc.input)
+	}
+}
+
+func TestWithAESGCM(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	enc, err := data.WithAESGCM(key, data.B64Encoder)
+	require.Nil(err, "%+v", err)
+
+	inputs := [][]byte{
+		[]byte("top secret"),
+		{0x00, 0x01, 0x02},
+		{},
+	}
+	for i, input := range inputs {
+		d1, err := enc.Marshal(input)
+		require.Nil(err, "%d: %+v", i, err)
+		d2, err := enc.Marshal(input)
+		require.Nil(err, "%d: %+v", i, err)
+		// random nonce, so always a different ciphertext
+		assert.NotEqual(d1, d2, "%d", i)
+
+		for _, d := range [][]byte{d1, d2} {
+			var output []byte
+			err = enc.Unmarshal(&output, d)
+			require.Nil(err, "%d: %+v", i, err)
+			assert.Equal(input, output, "%d", i)
+		}
+	}
+
+	d, err := enc.Marshal([]byte("top secret"))
+	require.Nil(err, "%+v", err)
+
+	// wrong key
+	other, err := data.WithAESGCM([]byte("fedcba9876543210fedcba9876543210"), data.B64Encoder)
+	require.Nil(err, "%+v", err)
+	output := []byte("keep")
+	err = other.Unmarshal(&output, d)
+	assert.NotNil(err)
+	assert.Equal([]byte("keep"), output)
+
+	// tampered or invalid ciphertext
+	var raw []byte
+	err = data.B64Encoder.Unmarshal(&raw, d)
+	require.Nil(err, "%+v", err)
+	raw[len(raw)-1] ^= 0x01
+	tampered, err := data.B64Encoder.Marshal(raw)
+	require.Nil(err, "%+v", err)
+	assert.NotNil(enc.Unmarshal(&output, tampered))
+	assert.NotNil(enc.Unmarshal(&output, []byte(`"Zm9v"`)))
+	assert.NotNil(enc.Unmarshal(&output, []byte(`0123`)))
+
+	// invalid key size
+	_, err = data.WithAESGCM([]byte("short"), data.B64Encoder)
+	assert.NotNil(err)
+}
+
+func TestWithLenientQuotes(t *testing.T) {
+	assert := assert.New(t)
+
+	lenientHex := data.WithLenientQuotes(data.HexEncoder)
+	lenientB64 := data.WithLenientQuotes(data.B64Encoder)
+	cases := []struct {
+		encoder  data.ByteEncoder
+		input    string
+		expected []byte
+	}{
+		{lenientHex, `'1a2b'`, []byte{0x1a, 0x2b}},
+		{lenientHex, ` '1a2b' `, []byte{0x1a, 0x2b}},
+		{lenientHex, `"1a2b"`, []byte{0x1a, 0x2b}},
+		{lenientHex, `''`, []byte{}},
+		{lenientB64, `'RCEuM3M='`, []byte("D!.3s")},
+		// still validates the content
+		{lenientHex, `'1a2'`, nil},
+		{lenientHex, `'1a2b"`, nil},
+		{lenientHex, `'`, nil},
+		{lenientB64, `'hey!'`, nil},
+		// strict by default
+		{data.HexEncoder, `'1a2b'`, nil},
+		{data.B64Encoder, `'RCEuM3M='`, nil},
+	}
+
+	for _, tc := range cases {
+		var output []byte
+		err := tc.encoder.Unmarshal(&output, []byte(tc.input))
+		if tc.expected == nil {
+			assert.NotNil(err, tc.input)
+		} else if assert.Nil(err, "%s: %+v", tc.input, err) {
+			assert.Equal(tc.expected, output, tc.input)
+		}
+	}
+
+	// marshal is unchanged
+	d, err := lenientHex.Marshal([]byte{0x1a, 0x2b})
+	assert.Nil(err, "%+v", err)
+	assert.Equal(`"1A2B"`, string(d))
+}
+
+func TestWithBytesObject(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	objHex := data.WithBytesObject(data.HexEncoder)
+	objB64 := data.WithBytesObject(data.B64Encoder)
+	cases := []struct {
+		encoder  data.ByteEncoder
+		input    string
+		expected []byte
+	}{
+		// object and string give the same result
+		{objHex, `{"bytes": "1a2b"}`, []byte{0x1a, 0x2b}},
+		{objHex, `"1a2b"`, []byte{0x1a, 0x2b}},
+		{objB64, ` {"bytes":"RCEuM3M="} `, []byte("D!.3s")},
+		{objB64, `"RCEuM3M="`, []byte("D!.3s")},
+		{objHex, `{"bytes": ""}`, []byte{}},
+		// other keys are ignored
+		{objHex, `{"type": "x", "bytes": "00"}`, []byte{0x00}},
+		// still validates the content
+		{objHex, `{"bytes": "1a2"}`, nil},
+		{objHex, `{"bytes": 12}`, nil},
+		{objHex, `{"byte": "1a2b"}`, nil},
+		{objHex, `{}`, nil},
+		{objHex, `{"bytes": "1a2b"`, nil},
+		// strict by default
+		{data.HexEncoder, `{"bytes": "1a2b"}`, nil},
+	}
+
+	for _, tc := range cases {
+		var output []byte
+		err := tc.encoder.Unmarshal(&output, []byte(tc.input))
+		if tc.expected == nil {
+			assert.NotNil(err, tc.input)
+		} else if assert.Nil(err, "%s: %+v", tc.input, err) {
+			assert.Equal(tc.expected, output, tc.input)
+		}
+	}
+
+	// marshals a plain string
+	out, err := objHex.Marshal([]byte{0x1a, 0x2b})
+	require.Nil(err, "%+v", err)
+	assert.Equal(`"1A2B"`, string(out))
+
+	// works through Bytes as the global encoder
+	defer data.SaveEncoder()()
+	data.Encoder = objB64
+	var msg struct {
+		A, B data.Bytes
+	}
+	err = json.Unmarshal([]byte(`{"A": {"bytes": "RCEuM3M="}, "B": "RCEuM3M="}`), &msg)
+	require.Nil(err, "%+v", err)
+	assert.Equal(data.Bytes("D!.3s"), msg.A)
+	assert.Equal(msg.A, msg.B)
+}
+
+// rawEncoder is a ByteEncoder that returns a slice of its input
+type rawEncoder struct{}
+
+func (rawEncoder) Marshal(b []byte) ([]byte, error) {
+	return append(append([]byte{'"'}, b...), '"'), nil
+}
+
+func (rawEncoder) Unmarshal(dst *[]byte, src []byte) error {
+	*dst = src[1 : len(src)-1]
+	return nil
+}
+
+// clobberEncoder is a ByteEncoder that sets dst even when it fails
+type clobberEncoder struct{}
+
+func (clobberEncoder) Marshal(b []byte) ([]byte, error) {
+	return nil, errors.New("clobber can't marshal")
+}
+
+func (clobberEncoder) Unmarshal(dst *[]byte, src []byte) error {
+	*dst = []byte("clobbered")
+	return errors.New("clobber can't unmarshal")
+}
+
+func TestWithCopyOutput(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	for _, enc := range []data.ByteEncoder{rawEncoder{}, data.WithCopyOutput(rawEncoder{})} {
+		buf := []byte(`"hello"`)
+		var out []byte
+		err := enc.Unmarshal(&out, buf)
+		require.Nil(err, "%+v", err)
+		assert.Equal([]byte("hello"), out)
+		copy(buf, "xxxxxxx")
+		if _, ok := enc.(rawEncoder); ok {
+			assert.Equal([]byte("xxxxx"), out)
+		} else {
+			assert.Equal([]byte("hello"), out)
+		}
+	}
+
+	// errors and marshal are passed on
+	copying := data.WithCopyOutput(data.HexEncoder)
+	var out []byte
+	assert.NotNil(copying.Unmarshal(&out, []byte(`"1a2"`)))
+	enc, err := copying.Marshal([]byte{0x1a, 0x2b})
+	require.Nil(err, "%+v", err)
+	assert.Equal(`"1A2B"`, string(enc))
+}
+
+func TestFallbackEncoder(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	hexFirst := data.NewFallbackEncoder(data.HexEncoder, data.B64Encoder)
+	chain := data.NewFallbackEncoder(data.HexEncoder, data.Base32CheckEncoder, data.B64Encoder)
+	cases := []struct {
+		encoder  data.ByteEncoder
+		input    string
+		expected []byte
+	}{
+		// primary works
+		{hexFirst, `"1A2B"`, []byte{0x1a, 0x2b}},
+		// fallback needed
+		{hexFirst, `"RCEuM3M="`, []byte("D!.3s")},
+		{chain, `"RCEuM3M="`, []byte("D!.3s")},
+		// first match wins: valid hex and base64
+		{hexFirst, `"abcd"`, []byte{0xab, 0xcd}},
+		{data.NewFallbackEncoder(data.B64Encoder, data.HexEncoder), `"abcd"`, []byte{0x69, 0xb7, 0x1d}},
+		// no fallbacks
+		{data.NewFallbackEncoder(data.HexEncoder), `"1A2B"`, []byte{0x1a, 0x2b}},
+		// all fail
+		{hexFirst, `"hey!"`, nil},
+		{chain, `"hey!"`, nil},
+		{data.NewFallbackEncoder(data.HexEncoder), `"RCEuM3M="`, nil},
+	}
+
+	for _, tc := range cases {
+		var output []byte
+		err := tc.encoder.Unmarshal(&output, []byte(tc.input))
+		if tc.expected == nil {
+			assert.NotNil(err, tc.input)
+		} else if assert.Nil(err, "%s: %+v", tc.input, err) {
+			assert.Equal(tc.expected, output, tc.input)
+		}
+	}
+
+	// dst is untouched if all fail, even by encoders that set it anyway
+	output := []byte("untouched")
+	for _, enc := range []data.ByteEncoder{
+		data.NewFallbackEncoder(clobberEncoder{}, data.HexEncoder),
+		data.NewFallbackEncoder(data.HexEncoder, clobberEncoder{}),
+	} {
+		assert.NotNil(enc.Unmarshal(&output, []byte(`"hey!"`)))
+		assert.Equal([]byte("untouched"), output)
+	}
+
+	// the error is the one from primary
+	primaryErr := data.HexEncoder.Unmarshal(&output, []byte(`"hey!"`))
+	err := hexFirst.Unmarshal(&output, []byte(`"hey!"`))
+	require.NotNil(err)
+	assert.Equal(primaryErr.Error(), err.Error())
+
+	// marshals with primary
+	out, err := hexFirst.Marshal([]byte{0x1a, 0x2b})
+	require.Nil(err, "%+v", err)
+	assert.Equal(`"1A2B"`, string(out))
+}
+
+func TestTrimTrailingNull(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	one := data.TrimTrailingNull(data.HexEncoder)
+	all := data.TrimAllTrailingNulls(data.HexEncoder)
+	cases := []struct {
+		input     string
+		one, all  []byte
+		malformed bool
+	}{
+		{`"41424300"`, []byte("ABC"), []byte("ABC"), false},
+		{`"414243"`, []byte("ABC"), []byte("ABC"), false},
+		{`"4142430000"`, []byte("ABC\x00"), []byte("ABC"), false},
+		// only trailing ones
+		{`"00414243"`, []byte("\x00ABC"), []byte("\x00ABC"), false},
+		{`"41004300"`, []byte("A\x00C"), []byte("A\x00C"), false},
+		{`"00"`, []byte{}, []byte{}, false},
+		{`"0000"`, []byte{0x00}, []byte{}, false},
+		{`""`, []byte{}, []byte{}, false},
+		// still validates the content
+		{`"4142430"`, nil, nil, true},
+	}
+
+	for _, tc := range cases {
+		var output []byte
+		err := one.Unmarshal(&output, []byte(tc.input))
+		if tc.malformed {
+			assert.NotNil(err, tc.input)
+		} else if assert.Nil(err, "%s: %+v", tc.input, err) {
+			assert.Equal(tc.one, output, tc.input)
+		}
+
+		err = all.Unmarshal(&output, []byte(tc.input))
+		if tc.malformed {
+			assert.NotNil(err, tc.input)
+		} else if assert.Nil(err, "%s: %+v", tc.input, err) {
+			assert.Equal(tc.all, output, tc.input)
+		}
+	}
+
+	// marshal is unchanged
+	out, err := one.Marshal([]byte("AB\x00"))
+	require.Nil(err, "%+v", err)
+	assert.Equal(`"414200"`, string(out))
+}
+
+func TestWithCRC32(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	crc := data.WithCRC32(data.HexEncoder)
+	cases := []struct {
+		input    []byte
+		expected string
+	}{
+		{[]byte{}, `"00000000"`},
+		{[]byte("123456789"), `"313233343536373839CBF43926"`},
+		{[]byte{0x1a, 0x2b}, `"1A2B5D481164"`},
+	}
+
+	for _, tc := range cases {
+		out, err := crc.Marshal(tc.input)
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.expected, string(out))
+
+		var back []byte
+		err = crc.Unmarshal(&back, out)
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.input, back)
+	}
+
+	// corrupted or truncated
+	bad := []string{
+		`"313233343536373838CBF43926"`,
+		`"313233343536373839CBF43927"`,
+		`"3132333435363738CBF43926"`,
+		`"CBF439"`,
+		`""`,
+		`"1A2B5D481165"`,
+	}
+	for _, input := range bad {
+		var back []byte
+		err := crc.Unmarshal(&back, []byte(input))
+		assert.NotNil(err, input)
+	}
+}
+
+func gzipped(t *testing.T, raw []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(raw)
+	require.Nil(t, err)
+	require.Nil(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestWithAutoGunzip(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	enc := data.WithAutoGunzip(data.B64Encoder, 1000)
+	text := []byte(strings.Repeat("compressible ", 20))
+	compressed := gzipped(t, text)
+
+	cases := []struct {
+		raw      []byte
+		expected []byte
+	}{
+		// gzipped payloads are decompressed
+		{compressed, text},
+		{gzipped(t, []byte{}), []byte{}},
+		// others are passed through
+		{text, text},
+		{[]byte{0x1f}, []byte{0x1f}},
+		{[]byte{0x8b, 0x1f}, []byte{0x8b, 0x1f}},
+		{[]byte{}, []byte{}},
+	}
+
+	for i, tc := range cases {
+		src, err := data.B64Encoder.Marshal(tc.raw)
+		require.Nil(err)
+		var res []byte
+		err = enc.Unmarshal(&res, src)
+		if assert.Nil(err, "%d: %+v", i, err) {
+			assert.Equal(tc.expected, res, "%d", i)
+		}
+	}
+
+	// marshal doesn't compress
+	out, err := enc.Marshal(text)
+	require.Nil(err)
+	expected, err := data.B64Encoder.Marshal(text)
+	require.Nil(err)
+	assert.Equal(expected, out)
+
+	// broken gzip streams are an error
+	corrupt := append([]byte{}, compressed...)
+	corrupt[len(corrupt)-6] ^= 0xff // in the crc
+	bad := [][]byte{
+		compressed[:len(compressed)-4],
+		compressed[:len(compressed)/2],
+		compressed[:12],
+		{0x1f, 0x8b},
+		corrupt,
+	}
+	for i, raw := range bad {
+		src, err := data.B64Encoder.Marshal(raw)
+		require.Nil(err)
+		var res []byte
+		assert.NotNil(enc.Unmarshal(&res, src), "%d", i)
+	}
+
+	// and so are values over the limit
+	small := data.WithAutoGunzip(data.B64Encoder, len(text)-1)
+	src, err := data.B64Encoder.Marshal(compressed)
+	require.Nil(err)
+	var res []byte
+	assert.NotNil(small.Unmarshal(&res, src))
+
+	exact := data.WithAutoGunzip(data.B64Encoder, len(text))
+	assert.Nil(exact.Unmarshal(&res, src))
+	assert.Equal(text, res)
+
+	// the largest limit doesn't overflow, a negative one is a bug
+	unlimited := data.WithAutoGunzip(data.B64Encoder, int(^uint(0)>>1))
+	require.Nil(unlimited.Unmarshal(&res, src))
+	assert.Equal(text, res)
+	assert.Panics(func() { data.WithAutoGunzip(data.B64Encoder, -1) })
+
+	// errors from inner are passed on
+	assert.NotNil(enc.Unmarshal(&res, []byte(`"not base64!"`)))
+}
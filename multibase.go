@@ -0,0 +1,168 @@
+package data
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MultibaseEncoder encodes bytes in the self-describing multibase format
+// used by IPFS, where the first character names the base, eg. "f1a2b" for
+// lowercase hex or "mGis" for base64:
+//
+//	f, F    base16, lower and upper case
+//	b, B    base32 (rfc4648), lower and upper case, without padding
+//	c, C    base32 with padding
+//	m, M    base64, without and with padding
+//	u, U    base64url, without and with padding
+//	z, Z    base58btc and base58flickr, if the base58 package is imported
+//
+// Marshal uses the base named by Default ('f' if unset). Unmarshal accepts
+// any of them (hex and base32 in either case, whatever the prefix says),
+// and rejects unknown prefixes. null and "" are empty bytes.
+type MultibaseEncoder struct {
+	Default byte
+}
+
+// multibase encodes and decodes one base, without the prefix
+type multibase struct {
+	encode func([]byte) (string, error)
+	decode func(string) ([]byte, error)
+}
+
+var multibases = map[byte]multibase{
+	'f': hexMultibase(false),
+	'F': hexMultibase(true),
+	'b': base32Multibase(base32.StdEncoding.WithPadding(base32.NoPadding), true),
+	'B': base32Multibase(base32.StdEncoding.WithPadding(base32.NoPadding), false),
+	'c': base32Multibase(base32.StdEncoding, true),
+	'C': base32Multibase(base32.StdEncoding, false),
+	'm': base64Multibase(base64.RawStdEncoding),
+	'M': base64Multibase(base64.StdEncoding),
+	'u': base64Multibase(base64.RawURLEncoding),
+	'U': base64Multibase(base64.URLEncoding),
+	'z': registeredMultibase("base58"),
+	'Z': registeredMultibase("base58flickr"),
+}
+
+func (e MultibaseEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (e MultibaseEncoder) Unmarshal(dst *[]byte, src []byte) error {
+	s, err := ParseJSONString(src)
+	if err != nil {
+		return err
+	}
+	if s == "" {
+		*dst = []byte{}
+		return nil
+	}
+	base, ok := multibases[s[0]]
+	if !ok {
+		return errors.Errorf("Unknown multibase prefix: %q", s[0])
+	}
+	res, err := base.decode(s[1:])
+	if err != nil {
+		return errors.Wrapf(err, "decode multibase %q", s[0])
+	}
+	*dst = res
+	return nil
+}
+
+func (e MultibaseEncoder) Marshal(bytes []byte) ([]byte, error) {
+	prefix := e.Default
+	if prefix == 0 {
+		prefix = 'f'
+	}
+	base, ok := multibases[prefix]
+	if !ok {
+		return nil, errors.Errorf("Unknown multibase prefix: %q", prefix)
+	}
+	s, err := base.encode(bytes)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(prefix) + s)
+}
+
+// hexMultibase decodes hex in any case, and encodes it in lower or upper
+func hexMultibase(upper bool) multibase {
+	return multibase{
+		encode: func(b []byte) (string, error) {
+			s := hex.EncodeToString(b)
+			if upper {
+				s = strings.ToUpper(s)
+			}
+			return s, nil
+		},
+		decode: hex.DecodeString,
+	}
+}
+
+// base32Multibase is like hexMultibase, for the upper case alphabet of enc
+func base32Multibase(enc *base32.Encoding, lower bool) multibase {
+	return multibase{
+		encode: func(b []byte) (string, error) {
+			s := enc.EncodeToString(b)
+			if lower {
+				s = strings.ToLower(s)
+			}
+			return s, nil
+		},
+		decode: func(s string) ([]byte, error) {
+			return enc.DecodeString(strings.ToUpper(s))
+		},
+	}
+}
+
+func base64Multibase(enc *base64.Encoding) multibase {
+	return multibase{
+		encode: func(b []byte) (string, error) {
+			return enc.EncodeToString(b), nil
+		},
+		decode: enc.DecodeString,
+	}
+}
+
+// registeredMultibase uses the encoder registered under name, which is
+// looked up on use, as packages like base58 register themselves in init
+func registeredMultibase(name string) multibase {
+	lookup := func() (ByteEncoder, error) {
+		enc, ok := EncoderByName(name)
+		if !ok {
+			return nil, errors.Errorf("Encoder %s is not registered", name)
+		}
+		return enc, nil
+	}
+	return multibase{
+		encode: func(b []byte) (string, error) {
+			enc, err := lookup()
+			if err != nil {
+				return "", err
+			}
+			out, err := enc.Marshal(b)
+			if err != nil {
+				return "", err
+			}
+			return ParseJSONString(out)
+		},
+		decode: func(s string) ([]byte, error) {
+			enc, err := lookup()
+			if err != nil {
+				return nil, err
+			}
+			quoted, err := json.Marshal(s)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			var res []byte
+			err = enc.Unmarshal(&res, quoted)
+			return res, err
+		},
+	}
+}
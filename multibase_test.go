@@ -0,0 +1,78 @@
+package data_test
+
+import (
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultibaseEncoder(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	// the examples from the multibase spec
+	spec := data.Bytes("yes mani !")
+	cases := []struct {
+		prefix   byte
+		input    data.Bytes
+		expected string
+	}{
+		{'f', spec, `"f796573206d616e692021"`},
+		{'F', spec, `"F796573206D616E692021"`},
+		{'b', spec, `"bpfsxgidnmfxgsibb"`},
+		{'B', spec, `"BPFSXGIDNMFXGSIBB"`},
+		{'c', spec, `"cpfsxgidnmfxgsibb"`},
+		{'C', data.Bytes("D!.3s"), `"CIQQS4M3T"`},
+		{'m', spec, `"meWVzIG1hbmkgIQ"`},
+		{'M', spec, `"MeWVzIG1hbmkgIQ=="`},
+		{'u', data.Bytes{0xfb, 0xff}, `"u-_8"`},
+		{'U', data.Bytes{0xfb, 0xff}, `"U-_8="`},
+		{'f', data.Bytes{}, `"f"`},
+		{'m', data.Bytes{}, `"m"`},
+		// the zero value is lowercase hex
+		{0, data.Bytes{0x1a, 0x2b}, `"f1a2b"`},
+	}
+
+	// any base decodes with any Default
+	dec := data.MultibaseEncoder{Default: 'm'}
+	for _, tc := range cases {
+		enc := data.MultibaseEncoder{Default: tc.prefix}
+		out, err := enc.Marshal(tc.input)
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.expected, string(out))
+
+		var back []byte
+		err = dec.Unmarshal(&back, out)
+		if assert.Nil(err, "%s: %+v", out, err) {
+			assert.Equal([]byte(tc.input), back, "%s", out)
+		}
+	}
+
+	// hex and base32 in the other case, and empty values
+	inputs := []struct {
+		input    string
+		expected data.Bytes
+	}{
+		{`"fDEADbeef"`, data.Bytes{0xde, 0xad, 0xbe, 0xef}},
+		{`"BpfsxGIDNMFXGSIBB"`, spec},
+		{`""`, data.Bytes{}},
+		{`null`, data.Bytes{}},
+	}
+	for _, tc := range inputs {
+		var back []byte
+		err := dec.Unmarshal(&back, []byte(tc.input))
+		if assert.Nil(err, "%s: %+v", tc.input, err) {
+			assert.Equal([]byte(tc.expected), back, tc.input)
+		}
+	}
+
+	// unknown prefixes, and invalid values
+	for _, bad := range []string{`"x1a2b"`, `"71a2b"`, `"1a2b"`, `"f1a2"`,
+		`"mRCEuM3M="`, `"M!!!!"`, `"bpfsxgidnmfxgsib1"`, `12`} {
+		var back []byte
+		assert.NotNil(dec.Unmarshal(&back, []byte(bad)), bad)
+	}
+	_, err := data.MultibaseEncoder{Default: 'x'}.Marshal(spec)
+	assert.NotNil(err)
+}
@@ -0,0 +1,112 @@
+package data
+
+import (
+	"math/big"
+	"math/bits"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// BigInt interprets the bytes as a big-endian unsigned integer.
+//
+// An empty (or nil) slice is 0
+func (b Bytes) BigInt() *big.Int {
+	return new(big.Int).SetBytes(b)
+}
+
+// BytesFromBigInt returns the minimal big-endian representation of the
+// absolute value of i (the sign is dropped, like big.Int.Bytes).
+//
+// 0 and nil both return an empty slice
+func BytesFromBigInt(i *big.Int) Bytes {
+	if i == nil {
+		return Bytes{}
+	}
+	return Bytes(i.Bytes())
+}
+
+// BitLen returns the position of the most significant set bit, treating
+// the bytes as a big-endian unsigned integer (like big.Int.BitLen). Leading
+// zero bytes do not count, so it is 0 for an all-zero or empty value.
+func (b Bytes) BitLen() int {
+	for i, c := range b {
+		if c != 0 {
+			return (len(b)-i-1)*8 + bits.Len8(c)
+		}
+	}
+	return 0
+}
+
+// Decimal formats the bytes as a fixed-point amount: the big-endian
+// unsigned integer (see BigInt) with the last scale digits after a decimal
+// point. {0x04, 0xd2} (1234) is "12.34" with scale 2, and "1234" with scale
+// 0. There are always exactly scale fractional digits, so 0 is "0.00".
+//
+// A negative scale is an error.
+func (b Bytes) Decimal(scale int) (string, error) {
+	if scale < 0 {
+		return "", errors.Errorf("Invalid scale: %d", scale)
+	}
+	digits := b.BigInt().String()
+	if scale == 0 {
+		return digits, nil
+	}
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	point := len(digits) - scale
+	return digits[:point] + "." + digits[point:], nil
+}
+
+// BytesFromDecimal parses a fixed-point amount like "12.34" into the
+// minimal big-endian bytes of the integer it is with scale fractional
+// digits (1234 for scale 2), as BytesFromBigInt would return them.
+//
+// The fraction may have fewer digits than scale ("12.3" is 1230), or more
+// if they are only trailing zeros. Anything that would need rounding is an
+// error, so no precision is lost silently. Signs, exponents and separators
+// are not allowed, and neither are a leading or trailing decimal point.
+func BytesFromDecimal(s string, scale int) (Bytes, error) {
+	res, err := parseDecimal(s, scale)
+	err = formatHook("decimal", len(s), res, err)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func parseDecimal(s string, scale int) (Bytes, error) {
+	if scale < 0 {
+		return nil, errors.Errorf("Invalid scale: %d", scale)
+	}
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+		if frac == "" {
+			return nil, errors.Errorf("Invalid decimal: %q", s)
+		}
+	}
+	if whole == "" || !isDigits(whole) || !isDigits(frac) {
+		return nil, errors.Errorf("Invalid decimal: %q", s)
+	}
+	if len(frac) > scale {
+		if strings.Trim(frac[scale:], "0") != "" {
+			return nil, errors.Errorf("Decimal %q has more than %d fractional digits", s, scale)
+		}
+		frac = frac[:scale]
+	}
+	frac += strings.Repeat("0", scale-len(frac))
+	num, _ := new(big.Int).SetString(whole+frac, 10)
+	return BytesFromBigInt(num), nil
+}
+
+// isDigits returns true if s only has the digits 0-9
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,155 @@
+package data_test
+
+import (
+	"math/big"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBigInt(t *testing.T) {
+	assert := assert.New(t)
+
+	large, _ := new(big.Int).SetString("1234567890abcdef1234567890abcdef", 16)
+	cases := []struct {
+		bytes data.Bytes
+		value *big.Int
+	}{
+		{data.Bytes{}, big.NewInt(0)},
+		{data.Bytes{0x01}, big.NewInt(1)},
+		{data.Bytes{0x01, 0x00}, big.NewInt(256)},
+		{data.Bytes{0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef,
+			0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef}, large},
+	}
+
+	for i, tc := range cases {
+		assert.Equal(0, tc.value.Cmp(tc.bytes.BigInt()), "%d", i)
+		assert.Equal(tc.bytes, data.BytesFromBigInt(tc.value), "%d", i)
+	}
+
+	// leading zeros are dropped, and so is the sign
+	assert.Equal(0, big.NewInt(5).Cmp(data.Bytes{0x00, 0x00, 0x05}.BigInt()))
+	assert.Equal(0, big.NewInt(0).Cmp(data.Bytes(nil).BigInt()))
+	assert.Equal(data.Bytes{0x01, 0x00}, data.BytesFromBigInt(big.NewInt(-256)))
+	assert.Equal(data.Bytes{}, data.BytesFromBigInt(nil))
+}
+
+func TestBitLen(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		bytes    data.Bytes
+		expected int
+	}{
+		{nil, 0},
+		{data.Bytes{}, 0},
+		{data.Bytes{0x00, 0x00}, 0},
+		{data.Bytes{0x01}, 1},
+		{data.Bytes{0x80}, 8},
+		{data.Bytes{0x80, 0x00}, 16},
+		{data.Bytes{0x01, 0xff}, 9},
+		// leading zeros don't count
+		{data.Bytes{0x00, 0x00, 0x01, 0x00}, 9},
+		{data.Bytes{0x00, 0x7f}, 7},
+	}
+
+	for i, tc := range cases {
+		assert.Equal(tc.expected, tc.bytes.BitLen(), "%d", i)
+		assert.Equal(tc.bytes.BigInt().BitLen(), tc.bytes.BitLen(), "%d", i)
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		bytes    data.Bytes
+		scale    int
+		expected string
+	}{
+		// zero
+		{nil, 2, "0.00"},
+		{data.Bytes{0x00, 0x00}, 0, "0"},
+		{data.Bytes{}, 8, "0.00000000"},
+		// with and without fractional parts
+		{data.Bytes{0x04, 0xd2}, 2, "12.34"},
+		{data.Bytes{0x04, 0xd2}, 0, "1234"},
+		{data.Bytes{0x04, 0xd2}, 4, "0.1234"},
+		{data.Bytes{0x04, 0xd2}, 6, "0.001234"},
+		{data.Bytes{0x04, 0xb0}, 2, "12.00"},
+		{data.Bytes{0x01}, 2, "0.01"},
+		{data.Bytes{0x03, 0xe8}, 3, "1.000"},
+		// more than 64 bits
+		{data.Bytes{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, 18, "18.446744073709551616"},
+	}
+
+	for _, tc := range cases {
+		s, err := tc.bytes.Decimal(tc.scale)
+		if assert.Nil(err, "%+v", err) {
+			assert.Equal(tc.expected, s, "%X scale %d", tc.bytes, tc.scale)
+		}
+		// and back, in the minimal form
+		b, err := data.BytesFromDecimal(tc.expected, tc.scale)
+		if assert.Nil(err, "%s: %+v", tc.expected, err) {
+			assert.Equal(data.BytesFromBigInt(tc.bytes.BigInt()), b, tc.expected)
+		}
+	}
+
+	_, err := data.Bytes{0x01}.Decimal(-1)
+	assert.NotNil(err)
+}
+
+func TestBytesFromDecimal(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		input    string
+		scale    int
+		expected int64
+	}{
+		{"0", 2, 0},
+		{"0.0", 2, 0},
+		{"12", 2, 1200},
+		{"12.3", 2, 1230},
+		{"12.34", 2, 1234},
+		{"0012.34", 2, 1234},
+		// extra digits are fine if they are zero
+		{"12.3400", 2, 1234},
+		{"12.000", 0, 12},
+		{"1.5", 1, 15},
+	}
+
+	for _, tc := range cases {
+		b, err := data.BytesFromDecimal(tc.input, tc.scale)
+		if assert.Nil(err, "%s: %+v", tc.input, err) {
+			assert.Equal(big.NewInt(tc.expected), b.BigInt(), tc.input)
+		}
+	}
+
+	errs := []struct {
+		input string
+		scale int
+	}{
+		// would need rounding
+		{"12.345", 2},
+		{"12.3401", 2},
+		{"0.5", 0},
+		{"0.000000001", 8},
+		// not plain decimals
+		{"", 2},
+		{"-1.00", 2},
+		{"+1.00", 2},
+		{"1,000.00", 2},
+		{"1e3", 2},
+		{".5", 2},
+		{"5.", 2},
+		{"1.2.3", 2},
+		{" 1.00", 2},
+		{"1.00", -1},
+	}
+	for _, tc := range errs {
+		_, err := data.BytesFromDecimal(tc.input, tc.scale)
+		assert.NotNil(err, "%q scale %d", tc.input, tc.scale)
+	}
+}
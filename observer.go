@@ -0,0 +1,102 @@
+package data
+
+import "sync/atomic"
+
+// Observer is notified of every value that Bytes (and SparseBytes,
+// TaggedBytes and CappedBytes) marshal to json, eg. to count them in
+// metrics, and of every decode the global validator checks (see
+// SetGlobalValidator).
+//
+// enc is the name the encoder is registered under, or its go type if it is
+// not registered. Formats that are not a ByteEncoder have a fixed name,
+// like "array" for Bytes decoded from a json array of integers, "binary"
+// for UnmarshalBinary or "hexdump" for ParseHexDump. For OnEncode, inLen
+// is the number of raw bytes and outLen that of the json output; for
+// OnDecode it is the other way around. outLen is 0 if err is not nil.
+//
+// The methods are called synchronously on the marshaling goroutine, so they
+// must be fast and safe for concurrent use. Calling the Marshal and
+// Unmarshal methods of a ByteEncoder directly (as the wrapping encoders do
+// with their inner ones) is not observed.
+type Observer interface {
+	OnEncode(enc string, inLen, outLen int, err error)
+	OnDecode(enc string, inLen, outLen int, err error)
+}
+
+// observerBox lets atomic.Value hold a nil Observer
+type observerBox struct {
+	o Observer
+}
+
+var observer atomic.Value
+
+// SetObserver sets the Observer notified of all encoding and decoding, or
+// removes it if o is nil (the default). Without an Observer, there is no
+// overhead beyond checking for one.
+func SetObserver(o Observer) {
+	observer.Store(observerBox{o})
+}
+
+// currentObserver returns the Observer, or nil
+func currentObserver() Observer {
+	box, _ := observer.Load().(observerBox)
+	return box.o
+}
+
+// marshalObserved calls enc.Marshal, notifying the Observer
+func marshalObserved(enc ByteEncoder, bytes []byte) ([]byte, error) {
+	res, err := enc.Marshal(bytes)
+	if o := currentObserver(); o != nil {
+		o.OnEncode(describeEncoder(enc), len(bytes), len(res), err)
+	}
+	return res, err
+}
+
+// unmarshalHooked calls enc.Unmarshal, and then the global validator (see
+// SetGlobalValidator), notifying the Observer of the result. dst is only
+// set if both succeed.
+func unmarshalHooked(enc ByteEncoder, dst *[]byte, src []byte) error {
+	var res []byte
+	err := enc.Unmarshal(&res, src)
+	err = decodeHook(enc, src, res, err)
+	if err != nil {
+		return err
+	}
+	*dst = res
+	return nil
+}
+
+// decodeHook runs the global validator on res, the result of decoding src
+// with enc, unless err is already set, and notifies the Observer. It
+// returns the resulting error.
+func decodeHook(enc ByteEncoder, src, res []byte, err error) error {
+	return hookDecoded(enc, "", len(src), res, err)
+}
+
+// formatHook is decodeHook for the formats that are not a ByteEncoder,
+// like hexdump or the binary wire format. The Observer sees them as
+// format, and inLen is the size of the input.
+func formatHook(format string, inLen int, res []byte, err error) error {
+	return hookDecoded(nil, format, inLen, res, err)
+}
+
+// hookDecoded implements decodeHook and formatHook. The encoder name is
+// only looked up if there is an Observer.
+func hookDecoded(enc ByteEncoder, format string, inLen int, res []byte, err error) error {
+	if err == nil {
+		if validate := currentValidator(); validate != nil {
+			err = validate(res)
+		}
+	}
+	if o := currentObserver(); o != nil {
+		n := 0
+		if err == nil {
+			n = len(res)
+		}
+		if enc != nil {
+			format = describeEncoder(enc)
+		}
+		o.OnDecode(format, inLen, n, err)
+	}
+	return err
+}
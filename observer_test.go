@@ -0,0 +1,135 @@
+package data_test
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type observed struct {
+	op            string
+	enc           string
+	inLen, outLen int
+	failed        bool
+}
+
+// recordingObserver implements data.Observer, remembering all calls
+type recordingObserver struct {
+	mtx   sync.Mutex
+	calls []observed
+}
+
+func (r *recordingObserver) OnEncode(enc string, inLen, outLen int, err error) {
+	r.record("encode", enc, inLen, outLen, err)
+}
+
+func (r *recordingObserver) OnDecode(enc string, inLen, outLen int, err error) {
+	r.record("decode", enc, inLen, outLen, err)
+}
+
+func (r *recordingObserver) record(op, enc string, inLen, outLen int, err error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.calls = append(r.calls, observed{op, enc, inLen, outLen, err != nil})
+}
+
+func (r *recordingObserver) take() []observed {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	res := r.calls
+	r.calls = nil
+	return res
+}
+
+func TestObserver(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	defer data.SaveEncoder()()
+	defer data.SetObserver(nil)
+	rec := &recordingObserver{}
+	data.SetObserver(rec)
+
+	// encode and decode, with the registered names
+	data.Encoder = data.HexEncoder
+	out, err := json.Marshal(data.Bytes{0x1a, 0x2b})
+	require.Nil(err)
+	var b data.Bytes
+	require.Nil(json.Unmarshal(out, &b))
+	assert.Equal([]observed{
+		{"encode", "hex", 2, 6, false},
+		{"decode", "hex", 6, 2, false},
+	}, rec.take())
+
+	data.Encoder = data.B64Encoder
+	require.Nil(json.Unmarshal([]byte(`"RCEuM3M="`), &b))
+	assert.NotNil(json.Unmarshal([]byte(`"RCEuM3M"`), &b))
+	assert.Equal([]observed{
+		{"decode", "base64", 10, 5, false},
+		{"decode", "base64", 9, 0, true},
+	}, rec.take())
+
+	// the integer array form, valid or not
+	require.Nil(json.Unmarshal([]byte(`[1,2]`), &b))
+	assert.NotNil(json.Unmarshal([]byte(`[256]`), &b))
+	assert.Equal([]observed{
+		{"decode", "array", 5, 2, false},
+		{"decode", "array", 5, 0, true},
+	}, rec.take())
+
+	// and the decode helpers
+	require.Nil(data.UnmarshalBounded(&b, []byte(`"1A2B"`), data.HexEncoder, 0, 4))
+	p, err := data.NewPooledDecoder(data.HexEncoder).Decode([]byte(`"1A"`))
+	require.Nil(err)
+	p.Release()
+	assert.Equal([]observed{
+		{"decode", "hex", 6, 2, false},
+		{"decode", "hex", 4, 1, false},
+	}, rec.take())
+
+	// other formats have fixed names
+	_, err = data.ParseHexDump("00000000  1a 2b")
+	require.Nil(err)
+	var u data.URLSafeBytes
+	require.Nil(u.UnmarshalText([]byte("Gis")))
+	_, _, err = data.UnmarshalWire([]byte{3})
+	assert.NotNil(err)
+	assert.Equal([]observed{
+		{"decode", "hexdump", 15, 2, false},
+		{"decode", "urlsafe", 3, 2, false},
+		{"decode", "wire", 1, 0, true},
+	}, rec.take())
+
+	// unregistered encoders are named by type
+	data.Encoder = data.HexFormat{Lower: true}
+	_, err = json.Marshal(struct {
+		A data.Bytes
+		B data.SparseBytes
+	}{data.Bytes{0x01}, data.SparseBytes{0x02}})
+	require.Nil(err)
+	assert.Equal([]observed{
+		{"encode", "data.HexFormat", 1, 4, false},
+		{"encode", "data.HexFormat", 1, 4, false},
+	}, rec.take())
+
+	// and overrides are seen
+	_, err = data.MarshalJSONWith(data.Bytes("D!.3s"), data.RawB64Encoder)
+	require.Nil(err)
+	assert.Equal([]observed{{"encode", "rawbase64", 5, 9, false}}, rec.take())
+
+	// TaggedBytes decode with the named encoder
+	var tagged data.TaggedBytes
+	require.Nil(json.Unmarshal([]byte(`{"enc":"hex","val":"1A2B3C"}`), &tagged))
+	assert.Equal([]observed{{"decode", "hex", 8, 3, false}}, rec.take())
+
+	// removing it is safe, and stops the calls
+	data.SetObserver(nil)
+	data.Encoder = data.HexEncoder
+	_, err = json.Marshal(data.Bytes{0x1a})
+	assert.Nil(err)
+	assert.Nil(json.Unmarshal([]byte(`"1A"`), &b))
+	assert.Empty(rec.take())
+}
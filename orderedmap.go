@@ -0,0 +1,48 @@
+package data
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// MarshalOrderedMap encodes the pairs keys[i]: vals[i] as a json object,
+// with the keys in ascending numeric order, like {"2": "1A", "10": "2B"}.
+//
+// This is for maps keyed by an integer enum, which encoding/json would
+// sort by their string form ("10" before "2"), or by the enum's String()
+// if it has one. The values are encoded like any Bytes. The keys must be
+// unique, and match vals in length.
+func MarshalOrderedMap(keys []int, vals []Bytes) ([]byte, error) {
+	if len(keys) != len(vals) {
+		return nil, errors.Errorf("Got %d keys for %d values", len(keys), len(vals))
+	}
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return keys[order[i]] < keys[order[j]] })
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for n, i := range order {
+		if n > 0 {
+			if keys[i] == keys[order[n-1]] {
+				return nil, errors.Errorf("Duplicate key: %d", keys[i])
+			}
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('"')
+		buf.WriteString(strconv.Itoa(keys[i]))
+		buf.WriteString(`":`)
+		val, err := vals[i].MarshalJSON()
+		if err != nil {
+			return nil, errors.Wrapf(err, "key %d", keys[i])
+		}
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,66 @@
+package data_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalOrderedMap(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	defer data.SaveEncoder()()
+	data.Encoder = data.HexEncoder
+
+	cases := []struct {
+		keys     []int
+		vals     []data.Bytes
+		expected string
+	}{
+		{nil, nil, `{}`},
+		{[]int{1}, []data.Bytes{{0x1a}}, `{"1":"1A"}`},
+		// numeric order, not string order
+		{[]int{10, 2, 1}, []data.Bytes{{0x10}, {0x02}, {0x01}}, `{"1":"01","2":"02","10":"10"}`},
+		{[]int{2, 10, 1}, []data.Bytes{{0x02}, {0x10}, {0x01}}, `{"1":"01","2":"02","10":"10"}`},
+		{[]int{1, 2, 10}, []data.Bytes{{0x01}, {0x02}, {0x10}}, `{"1":"01","2":"02","10":"10"}`},
+		{[]int{0, -5, 3}, []data.Bytes{{}, nil, {0xff}}, `{"-5":"","0":"","3":"FF"}`},
+	}
+
+	for i, tc := range cases {
+		out, err := data.MarshalOrderedMap(tc.keys, tc.vals)
+		require.Nil(err, "%d: %+v", i, err)
+		assert.Equal(tc.expected, string(out), "%d", i)
+
+		// still a valid json object
+		var parsed map[string]data.Bytes
+		require.Nil(json.Unmarshal(out, &parsed), "%d", i)
+		assert.Equal(len(tc.keys), len(parsed), "%d", i)
+	}
+
+	// uses Bytes encoding, including overrides
+	out, err := data.MarshalJSONWith(orderedMap{3: {0xfb}, 1: {0x00}}, data.B64Encoder)
+	require.Nil(err, "%+v", err)
+	assert.Equal(`{"1":"AA==","3":"-w=="}`, string(out))
+
+	// errors
+	_, err = data.MarshalOrderedMap([]int{1, 2}, []data.Bytes{{0x01}})
+	assert.NotNil(err)
+	_, err = data.MarshalOrderedMap([]int{1, 2, 1}, []data.Bytes{{0x01}, {0x02}, {0x03}})
+	assert.NotNil(err)
+}
+
+// orderedMap shows how a map type would use MarshalOrderedMap
+type orderedMap map[int]data.Bytes
+
+func (m orderedMap) MarshalJSON() ([]byte, error) {
+	keys := make([]int, 0, len(m))
+	vals := make([]data.Bytes, 0, len(m))
+	for k, v := range m {
+		keys = append(keys, k)
+		vals = append(vals, v)
+	}
+	return data.MarshalOrderedMap(keys, vals)
+}
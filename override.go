@@ -0,0 +1,111 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// overrides holds the encoders set by MarshalJSONWith, keyed by goroutine.
+//
+// encoding/json calls all MarshalJSON methods on the goroutine that called
+// json.Marshal, so this lets us change the encoding of every Bytes in a
+// value without touching the global Encoder. active counts the goroutines
+// with an override, so the common case never needs to look up the id.
+var overrides = struct {
+	sync.RWMutex
+	active int32
+	byID   map[uint64]ByteEncoder
+}{
+	byID: map[uint64]ByteEncoder{},
+}
+
+// MarshalJSONWith is like json.Marshal, but all Bytes inside of v are
+// encoded with enc, rather than the global Encoder.
+//
+// It is safe to call concurrently with different encoders.
+//
+// This has a cost for the whole process, though: while any MarshalJSONWith
+// is running, every Bytes.MarshalJSON on every goroutine has to find out
+// which goroutine it is on, from a stack trace. That makes each one several
+// times slower (see BenchmarkOverrideCost), so avoid calling this all the
+// time in a busy server. A type with its own MarshalJSON does not pay it.
+func MarshalJSONWith(v interface{}, enc ByteEncoder) ([]byte, error) {
+	restore := setOverride(enc)
+	defer restore()
+	return json.Marshal(v)
+}
+
+// readableEncoder is the grouped hex used by MarshalIndentReadable
+var readableEncoder = HexFormat{Separator: " ", GroupSize: 4}
+
+// MarshalIndentReadable produces indented json for humans to inspect, with
+// all Bytes inside of v shown as hex in groups of 4 bytes, like
+// "1A2B3C4D 5E6F".
+//
+// This is meant for admin and debug output. It is not meant to be parsed
+// again; if it is, the Bytes will only decode with a matching HexFormat.
+func MarshalIndentReadable(v interface{}) ([]byte, error) {
+	d, err := MarshalJSONWith(v, readableEncoder)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = json.Indent(&buf, d, "", "  ")
+	return buf.Bytes(), errors.WithStack(err)
+}
+
+// currentEncoder returns the encoder to use for Bytes on this goroutine
+func currentEncoder() ByteEncoder {
+	if atomic.LoadInt32(&overrides.active) == 0 {
+		return Encoder
+	}
+	id := goroutineID()
+	overrides.RLock()
+	defer overrides.RUnlock()
+	if enc, ok := overrides.byID[id]; ok {
+		return enc
+	}
+	return Encoder
+}
+
+// setOverride sets enc as the encoder for this goroutine, and returns a
+// function to restore the previous state
+func setOverride(enc ByteEncoder) func() {
+	id := goroutineID()
+	overrides.Lock()
+	defer overrides.Unlock()
+	prev, nested := overrides.byID[id]
+	overrides.byID[id] = enc
+	if !nested {
+		atomic.AddInt32(&overrides.active, 1)
+	}
+	return func() {
+		overrides.Lock()
+		defer overrides.Unlock()
+		if nested {
+			overrides.byID[id] = prev
+			return
+		}
+		delete(overrides.byID, id)
+		atomic.AddInt32(&overrides.active, -1)
+	}
+}
+
+// goroutineID parses the id of the current goroutine from its stack trace,
+// which starts with "goroutine 123 [running]:"
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
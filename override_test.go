@@ -0,0 +1,141 @@
+package data_test
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MData has Bytes at several levels
+type MData struct {
+	Name  string       `json:"name"`
+	Data  data.Bytes   `json:"data"`
+	Items []data.Bytes `json:"items"`
+	Key   KeyS         `json:"key"`
+}
+
+func TestMarshalJSONWith(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	data.Encoder = data.HexEncoder
+	in := MData{
+		Name:  "foo",
+		Data:  data.Bytes("D!.3s"),
+		Items: []data.Bytes{{0x0f, 0x8f}, {}},
+		Key:   KeyS{Cool{data.Bytes{0xfb, 0xed}}},
+	}
+	cases := []struct {
+		encoder  data.ByteEncoder
+		expected string
+	}{
+		{data.HexEncoder, `{"name":"foo","data":"44212E3373","items":["0F8F",""],"key":{"type":"cool","data":"FBED"}}`},
+		{data.B64Encoder, `{"name":"foo","data":"RCEuM3M=","items":["D48=",""],"key":{"type":"cool","data":"--0="}}`},
+		{data.RawB64Encoder, `{"name":"foo","data":"RCEuM3M","items":["D48",""],"key":{"type":"cool","data":"--0"}}`},
+	}
+
+	// run them all concurrently, many times
+	var wg sync.WaitGroup
+	results := make([][]string, len(cases))
+	for i, tc := range cases {
+		results[i] = make([]string, 50)
+		for j := range results[i] {
+			wg.Add(1)
+			go func(i, j int, enc data.ByteEncoder) {
+				defer wg.Done()
+				d, err := data.MarshalJSONWith(in, enc)
+				if err != nil {
+					results[i][j] = err.Error()
+					return
+				}
+				results[i][j] = string(d)
+			}(i, j, tc.encoder)
+		}
+	}
+	wg.Wait()
+
+	for i, tc := range cases {
+		for j := range results[i] {
+			require.Equal(tc.expected, results[i][j], "%d/%d", i, j)
+		}
+	}
+
+	// the global is untouched
+	assert.Equal(data.HexEncoder, data.Encoder)
+	d, err := json.Marshal(in.Data)
+	require.Nil(err, "%+v", err)
+	assert.Equal(`"44212E3373"`, string(d))
+}
+
+func TestMarshalIndentReadable(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	data.Encoder = data.B64Encoder
+	in := MData{
+		Name:  "foo",
+		Data:  data.Bytes{0x1a, 0x2b, 0x3c, 0x4d, 0x5e, 0x6f},
+		Items: []data.Bytes{{0x0f, 0x8f}},
+		Key:   KeyS{Cool{data.Bytes{0xfb, 0xed, 0x01, 0x02, 0x03}}},
+	}
+	d, err := data.MarshalIndentReadable(in)
+	require.Nil(err, "%+v", err)
+
+	expected := `{
+  "name": "foo",
+  "data": "1A2B3C4D 5E6F",
+  "items": [
+    "0F8F"
+  ],
+  "key": {
+    "type": "cool",
+    "data": "FBED0102 03"
+  }
+}`
+	assert.Equal(expected, string(d))
+	assert.True(json.Valid(d))
+	// the global is untouched
+	assert.Equal(data.B64Encoder, data.Encoder)
+	data.Encoder = data.HexEncoder
+}
+
+// blockingMarshaler blocks in MarshalJSON until release is closed
+type blockingMarshaler struct {
+	started chan<- struct{}
+	release <-chan struct{}
+}
+
+func (m blockingMarshaler) MarshalJSON() ([]byte, error) {
+	close(m.started)
+	<-m.release
+	return []byte(`null`), nil
+}
+
+// BenchmarkOverrideCost shows what a running MarshalJSONWith costs every
+// other Bytes.MarshalJSON in the process
+func BenchmarkOverrideCost(b *testing.B) {
+	value := data.Bytes("some 32 byte hash for comparison")
+	run := func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := value.MarshalJSON(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.Run("idle", run)
+	b.Run("override active", func(b *testing.B) {
+		started, release := make(chan struct{}), make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = data.MarshalJSONWith(blockingMarshaler{started, release}, data.B64Encoder)
+		}()
+		<-started
+		run(b)
+		close(release)
+		<-done
+	})
+}
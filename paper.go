@@ -0,0 +1,112 @@
+package data
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PaperBackupEncoder encodes bytes as numbered lines of base64 groups, for
+// printing keys on paper and typing them in again. With GroupsPerLine 4, a
+// 16 byte key looks like:
+//
+//	1: 0lC7 6TNd LEH+ 2qQ8
+//	2: zYLn MCHc uv0=
+//
+// A CRC-32 (IEEE) checksum of the bytes is appended before encoding, so a
+// missing or mistyped group is reported by Unmarshal instead of silently
+// giving the wrong key. The line numbers only help to find your place, and
+// Unmarshal ignores them, along with any whitespace.
+//
+// GroupSize is the number of characters per group (default 4), and
+// GroupsPerLine the number of groups per line (default 8).
+type PaperBackupEncoder struct {
+	GroupSize     int
+	GroupsPerLine int
+}
+
+func (e PaperBackupEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+// layout returns the group sizes, with defaults for the zero values
+func (e PaperBackupEncoder) layout() (size, perLine int) {
+	size, perLine = e.GroupSize, e.GroupsPerLine
+	if size <= 0 {
+		size = 4
+	}
+	if perLine <= 0 {
+		perLine = 8
+	}
+	return size, perLine
+}
+
+func (e PaperBackupEncoder) Unmarshal(dst *[]byte, src []byte) error {
+	s, err := ParseJSONString(src)
+	if err != nil {
+		return err
+	}
+	var text strings.Builder
+	for _, line := range strings.Split(s, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && strings.HasSuffix(fields[0], ":") {
+			fields = fields[1:]
+		}
+		for _, group := range fields {
+			text.WriteString(group)
+		}
+	}
+	raw, err := base64.StdEncoding.DecodeString(text.String())
+	if err != nil {
+		return errors.Wrap(err, "decode base64")
+	}
+	if len(raw) < crc32.Size {
+		return errors.Errorf("Too short for a checksum: %d bytes", len(raw))
+	}
+	payload, sum := raw[:len(raw)-crc32.Size], raw[len(raw)-crc32.Size:]
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(sum) {
+		return errors.New("Checksum mismatch, check for missing or mistyped groups")
+	}
+	*dst = payload
+	return nil
+}
+
+func (e PaperBackupEncoder) Marshal(bytes []byte) ([]byte, error) {
+	raw := make([]byte, len(bytes)+crc32.Size)
+	copy(raw, bytes)
+	binary.BigEndian.PutUint32(raw[len(bytes):], crc32.ChecksumIEEE(bytes))
+	text := base64.StdEncoding.EncodeToString(raw)
+
+	size, perLine := e.layout()
+	lineLen := size * perLine
+	lines := (len(text) + lineLen - 1) / lineLen
+	width := len(strconv.Itoa(lines))
+
+	var s strings.Builder
+	for i := 0; i < lines; i++ {
+		if i > 0 {
+			s.WriteByte('\n')
+		}
+		fmt.Fprintf(&s, "%*d:", width, i+1)
+		line := text[i*lineLen:]
+		if len(line) > lineLen {
+			line = line[:lineLen]
+		}
+		for len(line) > 0 {
+			n := size
+			if n > len(line) {
+				n = len(line)
+			}
+			s.WriteByte(' ')
+			s.WriteString(line[:n])
+			line = line[n:]
+		}
+	}
+	return json.Marshal(s.String())
+}
@@ -0,0 +1,107 @@
+package data_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaperBackupEncoder(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	key := data.Bytes{0xd2, 0x50, 0xbb, 0xe9, 0x33, 0x5d, 0x2c, 0x41,
+		0xfe, 0xda, 0xa4, 0x3c, 0xcd, 0x82, 0xe7, 0x30}
+
+	cases := []struct {
+		encoder  data.PaperBackupEncoder
+		input    data.Bytes
+		expected string
+	}{
+		{data.PaperBackupEncoder{GroupsPerLine: 4}, key,
+			"1: 0lC7 6TNd LEH+ 2qQ8\n2: zYLn MCHc uv0="},
+		{data.PaperBackupEncoder{}, key,
+			"1: 0lC7 6TNd LEH+ 2qQ8 zYLn MCHc uv0="},
+		{data.PaperBackupEncoder{GroupSize: 5, GroupsPerLine: 1}, key,
+			"1: 0lC76\n2: TNdLE\n3: H+2qQ\n4: 8zYLn\n5: MCHcu\n6: v0="},
+		{data.PaperBackupEncoder{GroupSize: 2, GroupsPerLine: 1}, key,
+			" 1: 0l\n 2: C7\n 3: 6T\n 4: Nd\n 5: LE\n 6: H+\n 7: 2q\n 8: Q8\n" +
+				" 9: zY\n10: Ln\n11: MC\n12: Hc\n13: uv\n14: 0="},
+		{data.PaperBackupEncoder{}, data.Bytes{0x1a, 0x2b}, "1: Gitd SBFk"},
+		{data.PaperBackupEncoder{}, data.Bytes{}, "1: AAAA AA=="},
+	}
+
+	for _, tc := range cases {
+		out, err := tc.encoder.Marshal(tc.input)
+		require.Nil(err, "%+v", err)
+		var text string
+		require.Nil(json.Unmarshal(out, &text))
+		assert.Equal(tc.expected, text)
+
+		var back []byte
+		err = tc.encoder.Unmarshal(&back, out)
+		if assert.Nil(err, "%s: %+v", text, err) {
+			assert.Equal([]byte(tc.input), back)
+		}
+	}
+
+	// a realistic 32 byte key, typed in with sloppy spacing and line ends
+	seed := data.Bytes("0123456789abcdef0123456789ABCDEF")
+	enc := data.PaperBackupEncoder{GroupSize: 4, GroupsPerLine: 3}
+	out, err := enc.Marshal(seed)
+	require.Nil(err)
+	var text string
+	require.Nil(json.Unmarshal(out, &text))
+	lines := strings.Split(text, "\n")
+	assert.Len(lines, 4)
+
+	typed := "\n  " + strings.Join(lines, "  \r\n\n") + "\n"
+	typed = strings.Replace(typed, " ", "   ", -1)
+	quoted, err := json.Marshal(typed)
+	require.Nil(err)
+	var back []byte
+	err = enc.Unmarshal(&back, quoted)
+	if assert.Nil(err, "%+v", err) {
+		assert.Equal([]byte(seed), back)
+	}
+
+	// line numbers are ignored, so any layout decodes any other
+	back = nil
+	require.Nil(data.PaperBackupEncoder{GroupSize: 7}.Unmarshal(&back, out))
+	assert.Equal([]byte(seed), back)
+
+	// dropping any group, line or character is detected
+	var broken []string
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		for j := 1; j < len(fields); j++ {
+			rest := append(append([]string{}, fields[:j]...), fields[j+1:]...)
+			broken = append(broken, replaceLine(lines, i, strings.Join(rest, " ")))
+		}
+		broken = append(broken, replaceLine(lines, i, ""))
+	}
+	broken = append(broken,
+		strings.Replace(text, lines[1][3:5], lines[1][4:5], 1),
+		strings.Replace(text, lines[2][3:5], lines[2][4:5]+lines[2][3:4], 1),
+		"", "1:",
+	)
+	for _, b := range broken {
+		quoted, err := json.Marshal(b)
+		require.Nil(err)
+		var res []byte
+		err = enc.Unmarshal(&res, quoted)
+		assert.NotNil(err, b)
+	}
+
+	// only strings
+	assert.NotNil(enc.Unmarshal(&back, []byte("123")))
+}
+
+func replaceLine(lines []string, i int, line string) string {
+	res := append([]string{}, lines...)
+	res[i] = line
+	return strings.Join(res, "\n")
+}
@@ -0,0 +1,153 @@
+package data
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// patch operations
+const (
+	opCopy   byte = 0 // offset, length: copy a run from old
+	opInsert byte = 1 // length, bytes: insert literal bytes
+)
+
+const maxInt = int(^uint(0) >> 1)
+
+// patchBlock is the minimum run length Patch looks for in old
+const patchBlock = 8
+
+// Patch returns a compact delta that turns old into new with Apply.
+//
+// The delta is a sequence of copy (a run of old) and insert (literal bytes)
+// operations. Runs are found by matching aligned 8 byte blocks of old, so it
+// works best for appends, insertions and localized edits, and degrades to
+// including new verbatim (plus a few bytes) if nothing matches.
+//
+// The patch starts with the lengths of old and new, so Apply detects most
+// attempts to patch the wrong value.
+func Patch(old, new Bytes) (patch Bytes, err error) {
+	index := make(map[uint64]int, len(old)/patchBlock)
+	for pos := 0; pos+patchBlock <= len(old); pos += patchBlock {
+		key := binary.LittleEndian.Uint64(old[pos:])
+		if _, ok := index[key]; !ok {
+			index[key] = pos
+		}
+	}
+
+	var buf [binary.MaxVarintLen64]byte
+	putUvarint := func(x int) {
+		n := binary.PutUvarint(buf[:], uint64(x))
+		patch = append(patch, buf[:n]...)
+	}
+	insert := func(lit []byte) {
+		if len(lit) > 0 {
+			patch = append(patch, opInsert)
+			putUvarint(len(lit))
+			patch = append(patch, lit...)
+		}
+	}
+
+	patch = Bytes{}
+	putUvarint(len(old))
+	putUvarint(len(new))
+
+	lit := 0 // start of the pending literal bytes in new
+	for i := 0; i+patchBlock <= len(new); {
+		pos, ok := index[binary.LittleEndian.Uint64(new[i:])]
+		if !ok {
+			i++
+			continue
+		}
+		// grow the match in both directions
+		start, from := i, pos
+		for start > lit && from > 0 && new[start-1] == old[from-1] {
+			start--
+			from--
+		}
+		end := i + patchBlock
+		for end < len(new) && from+end-start < len(old) && new[end] == old[from+end-start] {
+			end++
+		}
+		insert(new[lit:start])
+		patch = append(patch, opCopy)
+		putUvarint(from)
+		putUvarint(end - start)
+		lit, i = end, end
+	}
+	insert(new[lit:])
+	return patch, nil
+}
+
+// Apply reconstructs new from old and a patch created by Patch(old, new).
+//
+// It returns an error if the patch is malformed, or was not made for a value
+// of the length of old.
+func Apply(old, patch Bytes) (Bytes, error) {
+	readUvarint := func() (int, error) {
+		x, n := binary.Uvarint(patch)
+		if n <= 0 || x > uint64(maxInt) {
+			return 0, errors.New("Invalid patch: bad varint")
+		}
+		patch = patch[n:]
+		return int(x), nil
+	}
+
+	oldLen, err := readUvarint()
+	if err != nil {
+		return nil, err
+	}
+	if oldLen != len(old) {
+		return nil, errors.Errorf("Invalid patch: made for %d bytes, have %d", oldLen, len(old))
+	}
+	newLen, err := readUvarint()
+	if err != nil {
+		return nil, err
+	}
+
+	// a copy can reuse the same part of old many times, so newLen is not
+	// bounded by the input, only the initial allocation is
+	capacity := newLen
+	if limit := len(old) + len(patch); capacity > limit {
+		capacity = limit
+	}
+	res := make(Bytes, 0, capacity)
+	for len(patch) > 0 {
+		op := patch[0]
+		patch = patch[1:]
+		switch op {
+		case opCopy:
+			from, err := readUvarint()
+			if err != nil {
+				return nil, err
+			}
+			n, err := readUvarint()
+			if err != nil {
+				return nil, err
+			}
+			if from > len(old) || n > len(old)-from {
+				return nil, errors.Errorf("Invalid patch: copy [%d:%d] out of range", from, from+n)
+			}
+			res = append(res, old[from:from+n]...)
+		case opInsert:
+			n, err := readUvarint()
+			if err != nil {
+				return nil, err
+			}
+			if n > len(patch) {
+				return nil, errors.Errorf("Invalid patch: insert of %d bytes truncated", n)
+			}
+			res = append(res, patch[:n]...)
+			patch = patch[n:]
+		default:
+			return nil, errors.Errorf("Invalid patch: unknown operation %d", op)
+		}
+		if len(res) > newLen {
+			return nil, errors.Errorf("Invalid patch: result exceeds %d bytes", newLen)
+		}
+	}
+	if len(res) != newLen {
+		return nil, errors.Errorf("Invalid patch: result is %d bytes, expected %d", len(res), newLen)
+	}
+	return res, nil
+}
@@ -0,0 +1,111 @@
+package data_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randBytes(r *rand.Rand, n int) data.Bytes {
+	b := make(data.Bytes, n)
+	r.Read(b)
+	return b
+}
+
+func TestPatch(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+	r := rand.New(rand.NewSource(42))
+
+	base := randBytes(r, 4000)
+	edited := append(append(append(data.Bytes{}, base[:1000]...), "some edit"...), base[1200:]...)
+	moved := append(append(data.Bytes{}, base[2000:]...), base[:2000]...)
+
+	cases := []struct {
+		old, new data.Bytes
+		// upper bound for the patch size
+		max int
+	}{
+		{nil, nil, 4},
+		{data.Bytes{}, data.Bytes("hello"), 10},
+		{data.Bytes("hello"), data.Bytes{}, 4},
+		{data.Bytes("short"), data.Bytes("short"), 12},
+		// identical
+		{base, base, 12},
+		// append only
+		{base, append(append(data.Bytes{}, base...), "more data"...), 26},
+		{base[:3995], base, 20},
+		// prepend and local edits
+		{base, append(data.Bytes("header"), base...), 25},
+		{base, edited, 40},
+		{base, moved, 20},
+		// repeating old
+		{base[:64], data.Concat(base[:64], base[:64], base[:64], base[:64]), 20},
+		// fully different
+		{base, randBytes(r, 4000), 4010},
+		{base, randBytes(r, 100), 110},
+	}
+
+	for i, tc := range cases {
+		patch, err := data.Patch(tc.old, tc.new)
+		require.Nil(err, "%d: %+v", i, err)
+		assert.True(len(patch) <= tc.max, "%d: patch is %d bytes", i, len(patch))
+		res, err := data.Apply(tc.old, patch)
+		require.Nil(err, "%d: %+v", i, err)
+		assert.True(bytes.Equal(tc.new, res), "%d", i)
+	}
+
+	// random edits round trip
+	for i := 0; i < 50; i++ {
+		new := append(data.Bytes{}, base...)
+		for j := 0; j < 5; j++ {
+			pos := r.Intn(len(new) - 20)
+			new = append(append(new[:pos:pos], randBytes(r, r.Intn(20))...), new[pos+r.Intn(20):]...)
+		}
+		patch, err := data.Patch(base, new)
+		require.Nil(err, "%+v", err)
+		res, err := data.Apply(base, patch)
+		require.Nil(err, "%+v", err)
+		assert.True(bytes.Equal(new, res), "%d", i)
+		assert.True(len(patch) < len(new)/2, "%d: patch is %d bytes", i, len(patch))
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	old := data.Bytes("0123456789abcdef")
+	patch, err := data.Patch(old, data.Bytes("0123456789abcdef++"))
+	require.Nil(err, "%+v", err)
+
+	cases := []struct {
+		old, patch data.Bytes
+	}{
+		// wrong base
+		{old[:15], patch},
+		{append(data.Bytes("x"), old...), patch},
+		// truncated
+		{old, nil},
+		{old, patch[:1]},
+		{old, patch[:len(patch)-1]},
+		// trailing data
+		{old, append(append(data.Bytes{}, patch...), 1, 1, 'x')},
+		// copy out of range
+		{old, data.Bytes{16, 4, 0, 14, 4}},
+		{old, data.Bytes{16, 4, 0, 0xff, 0xff, 0xff, 0xff, 0x0f, 4}},
+		// bad operation
+		{old, data.Bytes{16, 1, 7}},
+		// result too long
+		{old, data.Bytes{16, 2, 0, 0, 4}},
+		// huge claimed length
+		{old, data.Bytes{16, 0xff, 0xff, 0xff, 0xff, 0x0f, 0, 0, 4}},
+	}
+
+	for i, tc := range cases {
+		_, err := data.Apply(tc.old, tc.patch)
+		assert.NotNil(err, "%d", i)
+	}
+}
@@ -0,0 +1,96 @@
+package data
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// PercentEncoder encodes byte slices as percent-encoded ascii strings,
+// suitable for use directly in url query parameters.
+var PercentEncoder ByteEncoder = percentEncoder{}
+
+const upperHex = "0123456789ABCDEF"
+
+// percentEncoder implements ByteEncoder encoding every byte outside of
+// the RFC 3986 unreserved set as %XX
+type percentEncoder struct{}
+
+func (e percentEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (_ percentEncoder) Unmarshal(dst *[]byte, src []byte) (err error) {
+	text, err := jsonText(src)
+	if err != nil {
+		return err
+	}
+	// check the escapes and count the result before allocating it
+	size := len(text)
+	for i := 0; i < len(text); i++ {
+		if text[i] != '%' {
+			continue
+		}
+		if i+2 >= len(text) {
+			return errors.Errorf("Truncated escape at offset %d", i)
+		}
+		_, ok1 := unhex(text[i+1])
+		_, ok2 := unhex(text[i+2])
+		if !ok1 || !ok2 {
+			return errors.Errorf("Invalid escape %q at offset %d", text[i:i+3], i)
+		}
+		size -= 2
+		i += 2
+	}
+
+	res := make([]byte, 0, size)
+	for i := 0; i < len(text); i++ {
+		if text[i] != '%' {
+			res = append(res, text[i])
+			continue
+		}
+		hi, _ := unhex(text[i+1])
+		lo, _ := unhex(text[i+2])
+		res = append(res, hi<<4|lo)
+		i += 2
+	}
+	*dst = res
+	return nil
+}
+
+func (_ percentEncoder) Marshal(bytes []byte) ([]byte, error) {
+	res := make([]byte, 0, len(bytes)*3)
+	for _, c := range bytes {
+		if isUnreserved(c) {
+			res = append(res, c)
+		} else {
+			res = append(res, '%', upperHex[c>>4], upperHex[c&0xf])
+		}
+	}
+	return json.Marshal(string(res))
+}
+
+// isUnreserved returns true for the characters that never need escaping
+// in a uri (RFC 3986, section 2.3)
+func isUnreserved(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	case c == '-', c == '.', c == '_', c == '~':
+		return true
+	}
+	return false
+}
+
+// unhex returns the value of one hex digit (either case)
+func unhex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
@@ -0,0 +1,56 @@
+package data_test
+
+import (
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentEncoder(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	pct := data.PercentEncoder
+	cases := []struct {
+		input    []byte
+		expected string
+	}{
+		// nothing to escape
+		{[]byte("foo-bar_1.2~"), `"foo-bar_1.2~"`},
+		{[]byte{}, `""`},
+		// needs escaping
+		{[]byte("a b&c=d"), `"a%20b%26c%3Dd"`},
+		{[]byte("D4/a++1="), `"D4%2Fa%2B%2B1%3D"`},
+		{[]byte{0x00, 0x7f, 0xff}, `"%00%7F%FF"`},
+	}
+
+	for i, tc := range cases {
+		enc, err := pct.Marshal(tc.input)
+		require.Nil(err, "%d: %+v", i, err)
+		assert.Equal(tc.expected, string(enc), "%d", i)
+
+		var output []byte
+		err = pct.Unmarshal(&output, enc)
+		require.Nil(err, "%d: %+v", i, err)
+		assert.Equal(tc.input, output, "%d", i)
+	}
+
+	// decoding is lenient for unescaped and lowercase input
+	var output []byte
+	err := pct.Unmarshal(&output, []byte(`"a b%2fc"`))
+	require.Nil(err, "%+v", err)
+	assert.Equal([]byte("a b/c"), output)
+
+	errs := []string{
+		`0123`,    // not in quotes
+		`"%"`,     // truncated
+		`"ab%4"`,  // truncated
+		`"%zz"`,   // invalid hex
+		`"%4g12"`, // invalid hex
+	}
+	for _, input := range errs {
+		err := pct.Unmarshal(&output, []byte(input))
+		assert.NotNil(err, input)
+	}
+}
@@ -0,0 +1,119 @@
+package data
+
+import "sync"
+
+// maxPooledSize is the largest buffer PooledDecoder keeps for reuse, so a
+// single huge value doesn't pin its memory forever
+const maxPooledSize = 64 << 10
+
+// appendDecoder is implemented by ByteEncoders that can decode into the
+// free capacity of buf, instead of allocating a new slice. It returns buf
+// with the decoded bytes appended.
+type appendDecoder interface {
+	appendDecode(buf []byte, src []byte) ([]byte, error)
+}
+
+// growBuffer returns buf with room for n more bytes, allocating a new one
+// if needed. The result is never nil.
+func growBuffer(buf []byte, n int) []byte {
+	if buf == nil || cap(buf)-len(buf) < n {
+		return append(make([]byte, 0, len(buf)+n), buf...)
+	}
+	return buf
+}
+
+// PooledDecoder decodes bytes into buffers from a sync.Pool, for hot paths
+// where the garbage from decoding many short lived values adds up:
+//
+//	val, err := dec.Decode(src)
+//	if err != nil {
+//		return err
+//	}
+//	defer val.Release()
+//	process(val.Bytes())
+//
+// Once Release is called, the handle and its bytes belong to the pool again
+// and are reused by a later Decode, so neither must be used anymore. Copy
+// the bytes first if they have to outlive the handle. Released buffers are
+// not cleared, and buffers over 64KB are left to the garbage collector.
+//
+// HexFormat and the base64 encoders (including custom alphabets) decode
+// straight into the pooled buffer. Other encoders decode as usual, and the
+// result is copied, so they don't save anything.
+//
+// A PooledDecoder is safe for concurrent use, a handle is not.
+type PooledDecoder struct {
+	enc  ByteEncoder
+	pool sync.Pool
+}
+
+// NewPooledDecoder returns a PooledDecoder decoding with enc
+func NewPooledDecoder(enc ByteEncoder) *PooledDecoder {
+	d := &PooledDecoder{enc: enc}
+	d.pool.New = func() interface{} {
+		return &PooledBytes{}
+	}
+	return d
+}
+
+// PooledBytes holds a value decoded by PooledDecoder, until Release
+type PooledBytes struct {
+	buf []byte
+	dec *PooledDecoder
+}
+
+// Bytes returns the decoded value, which is only valid until Release
+func (p *PooledBytes) Bytes() Bytes {
+	return p.buf
+}
+
+// Release returns the buffer to the pool it came from. Neither p nor the
+// bytes must be used after, and Release must only be called once.
+func (p *PooledBytes) Release() {
+	dec := p.dec
+	if dec == nil {
+		return
+	}
+	p.dec = nil
+	if cap(p.buf) > maxPooledSize {
+		p.buf = nil
+	}
+	p.buf = p.buf[:0]
+	dec.pool.Put(p)
+}
+
+// Decode decodes src (a json string, like for Unmarshal) into a pooled
+// buffer. Call Release on the result when done with it.
+func (d *PooledDecoder) Decode(src []byte) (*PooledBytes, error) {
+	p := d.pool.Get().(*PooledBytes)
+	res, err := appendDecoded(d.enc, p.buf[:0], src)
+	if err != nil {
+		d.pool.Put(p)
+		return nil, err
+	}
+	p.buf, p.dec = res, d
+	return p, nil
+}
+
+// appendDecoded appends src decoded with enc to buf, directly if enc is an
+// appendDecoder, and runs decodeHook on the result
+func appendDecoded(enc ByteEncoder, buf []byte, src []byte) ([]byte, error) {
+	if ad, ok := enc.(appendDecoder); ok {
+		res, err := ad.appendDecode(buf, src)
+		var decoded []byte
+		if err == nil {
+			decoded = res[len(buf):]
+		}
+		err = decodeHook(enc, src, decoded, err)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	var res []byte
+	err := unmarshalHooked(enc, &res, src)
+	if err != nil {
+		return nil, err
+	}
+	return append(growBuffer(buf, len(res)), res...), nil
+}
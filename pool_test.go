@@ -0,0 +1,149 @@
+package data_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPooledDecoder(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	crypt, err := data.NewCustomB64Encoder("./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz", 0)
+	require.Nil(err)
+	encoders := []data.ByteEncoder{
+		data.HexEncoder,
+		data.HexFormat{Lower: true, Prefix: "0x", Separator: ":", GroupSize: 2},
+		data.B64Encoder,
+		data.RawB64Encoder,
+		crypt,
+		// these decode as usual, and copy
+		data.PercentEncoder,
+		data.QRAlphanumEncoder,
+	}
+	inputs := []data.Bytes{
+		data.Bytes("D!.3s"),
+		data.Bytes{0x1a, 0x2b, 0x3c, 0x4d},
+		data.Bytes(strings.Repeat("a longer value ", 20)),
+		data.Bytes{0x00},
+		data.Bytes{},
+	}
+
+	for _, enc := range encoders {
+		dec := data.NewPooledDecoder(enc)
+		// several rounds, so buffers are reused for different sizes
+		for round := 0; round < 3; round++ {
+			for _, in := range inputs {
+				src, err := enc.Marshal(in)
+				require.Nil(err)
+				var expected []byte
+				require.Nil(enc.Unmarshal(&expected, src))
+
+				val, err := dec.Decode(src)
+				if assert.Nil(err, "%s: %+v", src, err) {
+					assert.True(bytes.Equal(expected, val.Bytes()), "%s", src)
+					assert.True(bytes.Equal(in, val.Bytes()), "%s", src)
+					val.Release()
+				}
+			}
+		}
+
+		// errors don't return a handle
+		val, err := dec.Decode([]byte(`"%zz!"`))
+		assert.NotNil(err)
+		assert.Nil(val)
+		val, err = dec.Decode([]byte(`123`))
+		assert.NotNil(err)
+		assert.Nil(val)
+	}
+}
+
+func TestPooledDecoderReuse(t *testing.T) {
+	assert := assert.New(t)
+
+	dec := data.NewPooledDecoder(data.HexEncoder)
+	src := []byte(`"1A2B3C4D"`)
+
+	// sync.Pool may drop items at any time (and does so on purpose under
+	// the race detector), so only require that it is reused at some point
+	reused := false
+	for i := 0; i < 100 && !reused; i++ {
+		val, err := dec.Decode(src)
+		if !assert.Nil(err) {
+			return
+		}
+		first := &val.Bytes()[0]
+		val.Release()
+
+		val, err = dec.Decode([]byte(`"FFEE"`))
+		if !assert.Nil(err) {
+			return
+		}
+		reused = &val.Bytes()[0] == first
+		assert.Equal(data.Bytes{0xff, 0xee}, val.Bytes())
+		val.Release()
+	}
+	assert.True(reused, "buffer was never reused")
+
+	// huge buffers are not kept
+	big, err := json.Marshal(strings.Repeat("00", 100<<10))
+	if !assert.Nil(err) {
+		return
+	}
+	for i := 0; i < 10; i++ {
+		val, err := dec.Decode(big)
+		if !assert.Nil(err) {
+			return
+		}
+		first := &val.Bytes()[0]
+		val.Release()
+
+		val, err = dec.Decode(src)
+		if !assert.Nil(err) {
+			return
+		}
+		assert.False(&val.Bytes()[0] == first, "huge buffer was reused")
+		val.Release()
+	}
+
+	// a second Release is ignored
+	val, err := dec.Decode(src)
+	if assert.Nil(err) {
+		val.Release()
+		assert.NotPanics(val.Release)
+	}
+}
+
+func BenchmarkPooledDecoder(b *testing.B) {
+	src, err := data.HexEncoder.Marshal([]byte(strings.Repeat("a 32 byte hash ", 2) + "!!"))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("pooled", func(b *testing.B) {
+		dec := data.NewPooledDecoder(data.HexEncoder)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			val, err := dec.Decode(src)
+			if err != nil {
+				b.Fatal(err)
+			}
+			val.Release()
+		}
+	})
+
+	b.Run("unmarshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var res []byte
+			if err := data.HexEncoder.Unmarshal(&res, src); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
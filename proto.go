@@ -0,0 +1,47 @@
+package data
+
+// BytesToProto returns b for a protobuf bytes field. It does not copy, as
+// protobuf fields are raw bytes already, but marks where values leave our
+// json encoding, so any checks for that boundary can be added here.
+func BytesToProto(b Bytes) []byte {
+	return []byte(b)
+}
+
+// BytesFromProto returns the bytes from a protobuf bytes field, without
+// copying. See BytesToProto.
+func BytesFromProto(p []byte) Bytes {
+	return Bytes(p)
+}
+
+// ProtoBytesValue is implemented by protobuf wrapper messages holding
+// bytes, like *wrapperspb.BytesValue from google.golang.org/protobuf.
+// The generated getter also works on a nil message, returning nil.
+type ProtoBytesValue interface {
+	GetValue() []byte
+}
+
+// BytesFromProtoValue returns the bytes of a wrapper message, or nil if v
+// or its message are nil (an unset optional field). To go the other way,
+// use the constructor of the wrapper, eg. wrapperspb.Bytes(BytesToProto(b)),
+// or SetProtoValue.
+func BytesFromProtoValue(v ProtoBytesValue) Bytes {
+	if v == nil {
+		return nil
+	}
+	return BytesFromProto(v.GetValue())
+}
+
+// ProtoBytesSetter is implemented by wrapper messages with a setter for
+// their bytes. The protobuf generators don't add one, but it is a one-line
+// method on the message type:
+//
+//	func (x *BytesValue) SetValue(v []byte) { x.Value = v }
+type ProtoBytesSetter interface {
+	SetValue(v []byte)
+}
+
+// SetProtoValue stores b in the wrapper message v, without copying, as
+// BytesToProto does. This is the counterpart of BytesFromProtoValue.
+func SetProtoValue(v ProtoBytesSetter, b Bytes) {
+	v.SetValue(BytesToProto(b))
+}
@@ -0,0 +1,69 @@
+package data_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// BytesValue mimics the generated wrapperspb.BytesValue
+type BytesValue struct {
+	Value []byte
+}
+
+func (x *BytesValue) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *BytesValue) SetValue(v []byte) {
+	x.Value = v
+}
+
+func wrapBytes(v []byte) *BytesValue {
+	return &BytesValue{Value: v}
+}
+
+func TestProto(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	defer data.SaveEncoder()()
+	data.Encoder = data.HexEncoder
+
+	cases := []data.Bytes{
+		{0x1a, 0x2b},
+		{},
+		nil,
+	}
+
+	for _, b := range cases {
+		// to protobuf and back
+		msg := wrapBytes(data.BytesToProto(b))
+		back := data.BytesFromProtoValue(msg)
+		assert.Equal(b, back)
+		assert.Equal(b == nil, back == nil)
+		assert.Equal(b, data.BytesFromProto(msg.Value))
+
+		// and through the setter
+		set := &BytesValue{Value: []byte("old")}
+		data.SetProtoValue(set, b)
+		assert.Equal(b, data.BytesFromProtoValue(set))
+		assert.Equal(b == nil, set.Value == nil)
+	}
+
+	// unset wrappers give nil
+	var unset *BytesValue
+	assert.Nil(data.BytesFromProtoValue(unset))
+	assert.Nil(data.BytesFromProtoValue(nil))
+
+	// and from there on to our json
+	msg := wrapBytes([]byte{0x1a, 0x2b})
+	out, err := json.Marshal(struct{ Data data.Bytes }{data.BytesFromProtoValue(msg)})
+	require.Nil(err, "%+v", err)
+	assert.Equal(`{"Data":"1A2B"}`, string(out))
+}
@@ -0,0 +1,178 @@
+package data
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// registry keeps track of the known ByteEncoders by name, and the content
+// types that map to them.
+//
+// The built-in encoders are registered as "hex", "base64", "rawbase64" and
+// "percent". Other packages (like base58) add their own in init()
+var registry = struct {
+	sync.RWMutex
+	byName        map[string]ByteEncoder
+	names         []string
+	byContentType map[string]string
+}{
+	byName: map[string]ByteEncoder{
+		"hex":       HexEncoder,
+		"base64":    B64Encoder,
+		"rawbase64": RawB64Encoder,
+		"percent":   PercentEncoder,
+	},
+	names: []string{"hex", "base64", "rawbase64", "percent"},
+	byContentType: map[string]string{
+		"application/hex":       "hex",
+		"application/base64":    "base64",
+		"application/base64url": "rawbase64",
+	},
+}
+
+// RegisterEncoder makes enc available under name, replacing any encoder
+// previously registered with the same name.
+//
+// The registry is append-only: there is no way to remove a name again, so
+// tests that register encoders should use names no real encoder would
+// take, like "data-go-test-hex".
+func RegisterEncoder(name string, enc ByteEncoder) {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.byName[name]; !ok {
+		registry.names = append(registry.names, name)
+	}
+	registry.byName[name] = enc
+}
+
+// EncoderByName returns the encoder registered under name, if any
+func EncoderByName(name string) (ByteEncoder, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	enc, ok := registry.byName[name]
+	return enc, ok
+}
+
+// EncoderName returns the name enc was registered under, if any.
+//
+// If the same encoder is registered under multiple names, the first
+// registered one is returned.
+func EncoderName(enc ByteEncoder) (string, bool) {
+	if enc == nil || !reflect.TypeOf(enc).Comparable() {
+		return "", false
+	}
+	registry.RLock()
+	defer registry.RUnlock()
+	for _, name := range registry.names {
+		if equalEncoders(registry.byName[name], enc) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// equalEncoders compares a and b with ==, treating them as different if
+// that panics. A comparable wrapper type can still hold an uncomparable
+// encoder in an interface field (like WithLenientQuotes(WithTag(...))),
+// which the type alone doesn't tell.
+func equalEncoders(a, b ByteEncoder) (equal bool) {
+	ta := reflect.TypeOf(a)
+	if ta != reflect.TypeOf(b) || ta == nil || !ta.Comparable() {
+		return a == nil && b == nil
+	}
+	defer func() {
+		if recover() != nil {
+			equal = false
+		}
+	}()
+	return a == b
+}
+
+// registeredNames returns the names of all encoders, in registration order
+func registeredNames() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	return append([]string(nil), registry.names...)
+}
+
+// AssertEncoder returns an error if the global Encoder is not expected.
+// It is meant for startup code, to catch an accidental override of the
+// default by some imported package:
+//
+//	if err := data.AssertEncoder(data.B64Encoder); err != nil {
+//		panic(err)
+//	}
+//
+// Encoders are the same if they are registered under the same name, or
+// otherwise compare equal.
+func AssertEncoder(expected ByteEncoder) error {
+	active := Encoder
+	if sameEncoder(active, expected) {
+		return nil
+	}
+	return errors.Errorf("Unexpected encoder: %s, expected %s",
+		describeEncoder(active), describeEncoder(expected))
+}
+
+func sameEncoder(a, b ByteEncoder) bool {
+	aName, aOk := EncoderName(a)
+	bName, bOk := EncoderName(b)
+	if aOk && bOk {
+		return aName == bName
+	}
+	return equalEncoders(a, b)
+}
+
+func describeEncoder(enc ByteEncoder) string {
+	if name, ok := EncoderName(enc); ok {
+		return name
+	}
+	return fmt.Sprintf("%T", enc)
+}
+
+// RegisterContentType maps a mime-like content type (eg. "application/hex")
+// to the encoder registered under name.
+//
+// It returns an error if no encoder is registered under that name.
+func RegisterContentType(contentType, name string) error {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.byName[name]; !ok {
+		return errors.Errorf("Unknown encoder: %s", name)
+	}
+	registry.byContentType[normalizeContentType(contentType)] = name
+	return nil
+}
+
+// EncoderForContentType returns the ByteEncoder for a content type, as
+// it may appear in an Accept or Content-Type header.
+//
+// Matching ignores case and any parameters, so "Application/Hex; q=0.9"
+// will find the encoder for "application/hex". A comma separated list, as
+// in an Accept header, returns the encoder for the first registered type
+// in it. The q values are not weighed, so list the preferred type first.
+func EncoderForContentType(contentType string) (ByteEncoder, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	for _, ct := range strings.Split(contentType, ",") {
+		name, ok := registry.byContentType[normalizeContentType(ct)]
+		if !ok {
+			continue
+		}
+		if enc, ok := registry.byName[name]; ok {
+			return enc, true
+		}
+	}
+	return nil, false
+}
+
+func normalizeContentType(ct string) string {
+	if i := strings.IndexByte(ct, ';'); i >= 0 {
+		ct = ct[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(ct))
+}
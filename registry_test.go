@@ -0,0 +1,149 @@
+package data_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoderByName(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		name     string
+		expected data.ByteEncoder
+	}{
+		{"hex", data.HexEncoder},
+		{"base64", data.B64Encoder},
+		{"rawbase64", data.RawB64Encoder},
+		{"percent", data.PercentEncoder},
+		{"missing", nil},
+	}
+
+	for _, tc := range cases {
+		enc, ok := data.EncoderByName(tc.name)
+		if tc.expected == nil {
+			assert.False(ok, tc.name)
+			continue
+		}
+		if assert.True(ok, tc.name) {
+			assert.Equal(tc.expected, enc, tc.name)
+			name, ok := data.EncoderName(enc)
+			assert.True(ok, tc.name)
+			assert.Equal(tc.name, name)
+		}
+	}
+}
+
+func TestEncoderForContentType(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cases := []struct {
+		contentType string
+		expected    data.ByteEncoder
+	}{
+		{"application/hex", data.HexEncoder},
+		{"application/base64", data.B64Encoder},
+		{"application/base64url", data.RawB64Encoder},
+		// ignore case and parameters
+		{"Application/HEX", data.HexEncoder},
+		{" application/base64; q=0.8", data.B64Encoder},
+		// accept lists give the first registered type
+		{"application/base64, application/hex;q=0.5", data.B64Encoder},
+		{"application/json, application/hex;q=0.5", data.HexEncoder},
+		{"text/html,application/xhtml+xml,*/*;q=0.8", nil},
+		// unknown
+		{"application/json", nil},
+		{"", nil},
+	}
+
+	for _, tc := range cases {
+		enc, ok := data.EncoderForContentType(tc.contentType)
+		if tc.expected == nil {
+			assert.False(ok, tc.contentType)
+		} else if assert.True(ok, tc.contentType) {
+			assert.Equal(tc.expected, enc, tc.contentType)
+		}
+	}
+
+	// custom registration
+	err := data.RegisterContentType("text/x-percent", "percent")
+	require.Nil(err, "%+v", err)
+	enc, ok := data.EncoderForContentType("text/x-percent")
+	require.True(ok)
+	assert.Equal(data.PercentEncoder, enc)
+
+	// must refer to a registered encoder
+	err = data.RegisterContentType("text/x-missing", "missing")
+	assert.NotNil(err)
+	_, ok = data.EncoderForContentType("text/x-missing")
+	assert.False(ok)
+	// the registry is append-only, so use names nothing else takes
+	data.RegisterEncoder("data-go-test-hex", data.HexEncoder)
+	err = data.RegisterContentType("text/x-data-go-test", "data-go-test-hex")
+	require.Nil(err, "%+v", err)
+	enc, ok = data.EncoderForContentType("text/x-data-go-test")
+	require.True(ok)
+	assert.Equal(data.HexEncoder, enc)
+}
+
+func TestAssertEncoder(t *testing.T) {
+	assert := assert.New(t)
+	defer data.SaveEncoder()()
+
+	cases := []struct {
+		active   data.ByteEncoder
+		expected data.ByteEncoder
+		ok       bool
+	}{
+		{data.HexEncoder, data.HexEncoder, true},
+		{data.HexFormat{}, data.HexEncoder, true},
+		{data.B64Encoder, data.B64Encoder, true},
+		{data.PercentEncoder, data.PercentEncoder, true},
+		// not registered, but equal
+		{data.HexFormat{Lower: true}, data.HexFormat{Lower: true}, true},
+		// mismatches
+		{data.B64Encoder, data.HexEncoder, false},
+		{data.B64Encoder, data.RawB64Encoder, false},
+		{data.HexFormat{Lower: true}, data.HexEncoder, false},
+		{data.HexEncoder, nil, false},
+	}
+
+	for i, tc := range cases {
+		data.Encoder = tc.active
+		err := data.AssertEncoder(tc.expected)
+		if tc.ok {
+			assert.Nil(err, "%d: %+v", i, err)
+		} else {
+			assert.NotNil(err, "%d", i)
+		}
+	}
+
+	data.Encoder = data.B64Encoder
+	err := data.AssertEncoder(data.HexEncoder)
+	if assert.NotNil(err) {
+		assert.Contains(err.Error(), "base64")
+		assert.Contains(err.Error(), "hex")
+	}
+
+	// a comparable wrapper around an uncomparable encoder can't be
+	// compared, but doesn't panic either, registered or not
+	nested := func() data.ByteEncoder {
+		return data.WithLenientQuotes(data.WithTag([]byte{1}, data.HexEncoder))
+	}
+	data.Encoder = nested()
+	assert.NotPanics(func() {
+		assert.NotNil(data.AssertEncoder(nested()))
+	})
+	data.RegisterEncoder("data-go-test-nested", nested())
+	assert.NotPanics(func() {
+		_, ok := data.EncoderName(nested())
+		assert.False(ok)
+		assert.NotNil(data.AssertEncoder(nested()))
+		_, err = json.Marshal(data.Bytes{0x1a})
+		assert.Nil(err)
+	})
+}
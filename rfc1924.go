@@ -0,0 +1,114 @@
+package data
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RFC1924Encoder encodes bytes with the base85 alphabet of RFC 1924, which
+// (unlike Ascii85) avoids quotes, backslash, comma and period.
+//
+// The whole value is converted as one big-endian number, so a 16 byte
+// value like an IPv6 address always takes 20 characters, as in the RFC.
+// Other lengths work the same way, using the least number of characters
+// that can hold any value of that length. This is quadratic in the length,
+// so it is meant for short values: Marshal and Unmarshal reject values of
+// more than 1024 bytes (1279 characters), before converting anything.
+var RFC1924Encoder ByteEncoder = rfc1924Encoder{}
+
+// maxRFC1924Bytes is the longest value RFC1924Encoder converts
+const maxRFC1924Bytes = 1024
+
+const rfc1924Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz!#$%&()*+-;<=>?@^_`{|}~"
+
+var (
+	big85 = big.NewInt(85)
+	// rfc1924Lens[n] is the encoded length of n bytes, up to maxRFC1924Bytes
+	rfc1924Lens = rfc1924LenTable(maxRFC1924Bytes)
+)
+
+// rfc1924LenTable returns the encoded lengths of 0 to max bytes: for each
+// n, the smallest d with 85^d >= 256^n
+func rfc1924LenTable(max int) []int {
+	res := make([]int, max+1)
+	pow85, pow256 := big.NewInt(1), big.NewInt(1)
+	d := 0
+	for n := range res {
+		if n > 0 {
+			pow256.Lsh(pow256, 8)
+		}
+		for pow85.Cmp(pow256) < 0 {
+			pow85.Mul(pow85, big85)
+			d++
+		}
+		res[n] = d
+	}
+	return res
+}
+
+// rfc1924Encoder implements ByteEncoder and LenEncoder
+type rfc1924Encoder struct{}
+
+func (e rfc1924Encoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+func (_ rfc1924Encoder) Unmarshal(dst *[]byte, src []byte) (err error) {
+	s, err := ParseJSONString(src)
+	if err != nil {
+		return err
+	}
+	if max := rfc1924Lens[maxRFC1924Bytes]; len(s) > max {
+		return errors.Errorf("Base85 value exceeds %d characters", max)
+	}
+	// the lengths grow strictly, so at most one n matches
+	n := sort.SearchInts(rfc1924Lens, len(s))
+	if rfc1924Lens[n] != len(s) {
+		return errors.Errorf("Invalid base85 length: %d", len(s))
+	}
+	num, digit := new(big.Int), new(big.Int)
+	for i := 0; i < len(s); i++ {
+		v := strings.IndexByte(rfc1924Alphabet, s[i])
+		if v < 0 {
+			return errors.Errorf("Encountered unknown character: %s", string(s[i]))
+		}
+		num.Mul(num, big85)
+		num.Add(num, digit.SetInt64(int64(v)))
+	}
+	if num.BitLen() > n*8 {
+		return errors.Errorf("Base85 value overflows %d bytes", n)
+	}
+	*dst = num.FillBytes(make([]byte, n))
+	return nil
+}
+
+func (e rfc1924Encoder) Marshal(bytes []byte) ([]byte, error) {
+	if len(bytes) > maxRFC1924Bytes {
+		return nil, errors.Errorf("Value exceeds %d bytes for base85", maxRFC1924Bytes)
+	}
+	// the alphabet needs no json escaping
+	res := make([]byte, e.EncodedLen(len(bytes))+2)
+	res[0], res[len(res)-1] = '"', '"'
+	num, rem := new(big.Int).SetBytes(bytes), new(big.Int)
+	for i := len(res) - 2; i > 0; i-- {
+		num.QuoRem(num, big85, rem)
+		res[i] = rfc1924Alphabet[rem.Int64()]
+	}
+	return res, nil
+}
+
+// EncodedLen returns the number of characters for n bytes: the smallest d
+// with 85^d >= 256^n. Lengths over the limit of Marshal are computed on
+// demand, which is slow.
+func (_ rfc1924Encoder) EncodedLen(n int) int {
+	if n < 0 {
+		return 0
+	}
+	if n <= maxRFC1924Bytes {
+		return rfc1924Lens[n]
+	}
+	return rfc1924LenTable(n)[n]
+}
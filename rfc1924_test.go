@@ -0,0 +1,102 @@
+package data_test
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRFC1924Encoder(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cases := []struct {
+		input    []byte
+		expected string
+	}{
+		// the example from the RFC
+		{net.ParseIP("1080:0:0:0:8:800:200C:417A"), "4)+k&C#VzJ4br>0wv%Yp"},
+		{make([]byte, 16), "00000000000000000000"},
+		{[]byte{}, ""},
+		{[]byte{0x00}, "00"},
+		{[]byte{0xff}, "30"},
+		{[]byte("foo"), "A_D*"},
+		{[]byte{0xff, 0xff, 0xff, 0xff}, "|NsC0"},
+	}
+
+	for _, tc := range cases {
+		out, err := data.RFC1924Encoder.Marshal(tc.input)
+		require.Nil(err, "%+v", err)
+		assert.Equal(`"`+tc.expected+`"`, string(out))
+
+		var back []byte
+		err = data.RFC1924Encoder.Unmarshal(&back, out)
+		require.Nil(err, "%s: %+v", tc.expected, err)
+		assert.Equal(tc.input, back, tc.expected)
+
+		n, ok := data.EncodedLen(data.RFC1924Encoder, len(tc.input))
+		assert.True(ok)
+		assert.Equal(len(tc.expected), n)
+	}
+
+	// every length round trips
+	for n := 0; n < 70; n++ {
+		input := make([]byte, n)
+		for i := range input {
+			input[i] = byte(255 - i)
+		}
+		out, err := data.RFC1924Encoder.Marshal(input)
+		require.Nil(err, "%+v", err)
+		var back []byte
+		err = data.RFC1924Encoder.Unmarshal(&back, out)
+		require.Nil(err, "%d: %+v", n, err)
+		assert.Equal(input, back, "%d", n)
+	}
+}
+
+func TestRFC1924EncoderErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []string{
+		// invalid symbols
+		`"4)+k&C#VzJ4br>0wv%Y."`,
+		`"4)+k&C#VzJ4br>0wv%Y'"`,
+		`"4)+k&C#VzJ4br>0wv%Y\""`,
+		`"4)+k&C#VzJ4br>0wv%Y "`,
+		// lengths no byte count encodes to
+		`"0"`,
+		`"000000"`,
+		`"4)+k&C#VzJ4br>0wv%Yp0"`,
+		// more than 16 bytes worth
+		`"~~~~~~~~~~~~~~~~~~~~"`,
+		`"40"`,
+		// not a string
+		`1234`,
+		// over the limit, before looking at the content
+		`"` + strings.Repeat("0", 1280) + `"`,
+		`"` + strings.Repeat("?", 100000) + `"`,
+	}
+
+	for _, tc := range cases {
+		var out []byte
+		err := data.RFC1924Encoder.Unmarshal(&out, []byte(tc))
+		assert.NotNil(err, tc)
+	}
+
+	// the limit is 1024 bytes both ways
+	max := bytes.Repeat([]byte{0xff}, 1024)
+	out, err := data.RFC1924Encoder.Marshal(max)
+	assert.Nil(err, "%+v", err)
+	assert.Equal(1279+2, len(out))
+	var back []byte
+	assert.Nil(data.RFC1924Encoder.Unmarshal(&back, out))
+	assert.Equal(max, back)
+	_, err = data.RFC1924Encoder.Marshal(append(max, 0))
+	assert.NotNil(err)
+	n, _ := data.EncodedLen(data.RFC1924Encoder, 1025)
+	assert.Equal(1280, n)
+}
@@ -0,0 +1,76 @@
+package data
+
+import (
+	"bytes"
+	"sort"
+)
+
+// BytesUnion returns the values that are in a or b, eg. to merge two lists
+// of hashes. Like BytesIntersection and BytesDifference, it treats the
+// inputs as sets: they don't have to be sorted, duplicates count once, and
+// nil is the same as empty. The result is sorted (by bytes.Compare),
+// without duplicates, and holds copies, so it doesn't alias the inputs.
+func BytesUnion(a, b []Bytes) []Bytes {
+	return mergeSets(a, b, true, true, true)
+}
+
+// BytesIntersection returns the values that are in both a and b, see
+// BytesUnion.
+func BytesIntersection(a, b []Bytes) []Bytes {
+	return mergeSets(a, b, false, true, false)
+}
+
+// BytesDifference returns the values in a that are not in b, see
+// BytesUnion.
+func BytesDifference(a, b []Bytes) []Bytes {
+	return mergeSets(a, b, true, false, false)
+}
+
+// mergeSets walks the sorted sets a and b in step, keeping the values only
+// in a, in both, or only in b as requested
+func mergeSets(a, b []Bytes, onlyA, both, onlyB bool) []Bytes {
+	a, b = sortedSet(a), sortedSet(b)
+	res := []Bytes{}
+	keep := func(v Bytes, ok bool) {
+		if ok {
+			res = append(res, append(Bytes{}, v...))
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch c := bytes.Compare(a[i], b[j]); {
+		case c < 0:
+			keep(a[i], onlyA)
+			i++
+		case c > 0:
+			keep(b[j], onlyB)
+			j++
+		default:
+			keep(a[i], both)
+			i++
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		keep(a[i], onlyA)
+	}
+	for ; j < len(b); j++ {
+		keep(b[j], onlyB)
+	}
+	return res
+}
+
+// sortedSet returns a sorted copy of items (not of the values), without
+// duplicates
+func sortedSet(items []Bytes) []Bytes {
+	res := append([]Bytes(nil), items...)
+	sort.Slice(res, func(i, j int) bool { return bytes.Compare(res[i], res[j]) < 0 })
+	n := 0
+	for i, v := range res {
+		if i == 0 || !bytes.Equal(v, res[n-1]) {
+			res[n] = v
+			n++
+		}
+	}
+	return res[:n]
+}
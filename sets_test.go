@@ -0,0 +1,55 @@
+package data_test
+
+import (
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBytesSets(t *testing.T) {
+	assert := assert.New(t)
+
+	h1, h2, h3, h4 := data.Bytes{0x01}, data.Bytes{0x02, 0x00}, data.Bytes{0x02, 0x01}, data.Bytes{0xff}
+	cases := []struct {
+		a, b         []data.Bytes
+		union        []data.Bytes
+		intersection []data.Bytes
+		difference   []data.Bytes
+	}{
+		// disjoint
+		{[]data.Bytes{h1, h3}, []data.Bytes{h2, h4},
+			[]data.Bytes{h1, h2, h3, h4}, []data.Bytes{}, []data.Bytes{h1, h3}},
+		// overlapping, and unsorted
+		{[]data.Bytes{h3, h1, h2}, []data.Bytes{h4, h2},
+			[]data.Bytes{h1, h2, h3, h4}, []data.Bytes{h2}, []data.Bytes{h1, h3}},
+		// identical
+		{[]data.Bytes{h1, h2}, []data.Bytes{h2, h1},
+			[]data.Bytes{h1, h2}, []data.Bytes{h1, h2}, []data.Bytes{}},
+		// duplicates count once
+		{[]data.Bytes{h1, h1, h3, h1}, []data.Bytes{h3, h3, h4},
+			[]data.Bytes{h1, h3, h4}, []data.Bytes{h3}, []data.Bytes{h1}},
+		// empty sets
+		{nil, []data.Bytes{h2, h1},
+			[]data.Bytes{h1, h2}, []data.Bytes{}, []data.Bytes{}},
+		{[]data.Bytes{h2, h1}, nil,
+			[]data.Bytes{h1, h2}, []data.Bytes{}, []data.Bytes{h1, h2}},
+		{nil, nil, []data.Bytes{}, []data.Bytes{}, []data.Bytes{}},
+		// empty values are values, and nil is the same
+		{[]data.Bytes{{}, h1}, []data.Bytes{nil},
+			[]data.Bytes{{}, h1}, []data.Bytes{{}}, []data.Bytes{h1}},
+	}
+
+	for i, tc := range cases {
+		assert.Equal(tc.union, data.BytesUnion(tc.a, tc.b), "union %d", i)
+		assert.Equal(tc.intersection, data.BytesIntersection(tc.a, tc.b), "intersection %d", i)
+		assert.Equal(tc.difference, data.BytesDifference(tc.a, tc.b), "difference %d", i)
+	}
+
+	// the inputs are neither reordered nor aliased
+	a, b := []data.Bytes{h3, h1}, []data.Bytes{h3}
+	res := data.BytesIntersection(a, b)
+	assert.Equal([]data.Bytes{h3, h1}, a)
+	res[0][0] = 0xee
+	assert.Equal(data.Bytes{0x02, 0x01}, h3)
+}
@@ -0,0 +1,37 @@
+package data
+
+// SparseBytes is a byte slice that leaves out values that are all zero
+// bytes, like an unset hash or key: they are marshaled as "" instead of
+// the full encoding. Everything else is marshaled with the global Encoder,
+// like Bytes.
+//
+// The length of a zero value is not kept, so "" unmarshals to empty bytes,
+// as every encoder does. Fixed size fields get it back with PadLeft:
+//
+//	hash := data.Bytes(s.Hash).PadLeft(32, 0)
+//
+// IsZero reports the same values, so with the omitzero option of Go 1.24
+// encoding/json they are left out of the output completely. omitempty only
+// leaves out empty values, which already marshal as "".
+type SparseBytes []byte
+
+// IsZero returns true if s is empty or all zero bytes
+func (s SparseBytes) IsZero() bool {
+	for _, c := range s {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (s SparseBytes) MarshalJSON() ([]byte, error) {
+	if s.IsZero() {
+		return []byte(`""`), nil
+	}
+	return marshalObserved(currentEncoder(), s)
+}
+
+func (s *SparseBytes) UnmarshalJSON(data []byte) error {
+	return (*Bytes)(s).UnmarshalJSON(data)
+}
@@ -0,0 +1,84 @@
+package data_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type SData struct {
+	Hash data.SparseBytes `json:"hash"`
+	Sig  data.SparseBytes `json:"sig,omitempty"`
+}
+
+func TestSparseBytes(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	defer data.SaveEncoder()()
+
+	cases := []struct {
+		encoder  data.ByteEncoder
+		input    data.SparseBytes
+		expected string
+		zero     bool
+	}{
+		// all zero
+		{data.HexEncoder, make(data.SparseBytes, 32), `""`, true},
+		{data.B64Encoder, data.SparseBytes{0x00}, `""`, true},
+		{data.HexEncoder, data.SparseBytes{}, `""`, true},
+		{data.HexEncoder, nil, `""`, true},
+		// partially zero
+		{data.HexEncoder, data.SparseBytes{0x00, 0x00, 0x01}, `"000001"`, false},
+		{data.HexEncoder, data.SparseBytes{0x10, 0x00}, `"1000"`, false},
+		{data.B64Encoder, data.SparseBytes{0x00, 0x00, 0x01}, `"AAAB"`, false},
+		// non-zero
+		{data.HexEncoder, data.SparseBytes{0x1a, 0x2b}, `"1A2B"`, false},
+		{data.RawB64Encoder, data.SparseBytes("D!.3s"), `"RCEuM3M"`, false},
+	}
+
+	for i, tc := range cases {
+		data.Encoder = tc.encoder
+		assert.Equal(tc.zero, tc.input.IsZero(), "%d", i)
+
+		out, err := json.Marshal(tc.input)
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.expected, string(out), "%d", i)
+
+		var back data.SparseBytes
+		err = json.Unmarshal(out, &back)
+		require.Nil(err, "%d: %+v", i, err)
+		if tc.zero {
+			// the length is gone, but it is still zero
+			assert.Equal(data.SparseBytes{}, back, "%d", i)
+			assert.Equal(data.Bytes(make([]byte, len(tc.input))), data.Bytes(back).PadLeft(len(tc.input), 0))
+		} else {
+			assert.Equal(tc.input, back, "%d", i)
+		}
+	}
+
+	// in a struct
+	data.Encoder = data.HexEncoder
+	in := SData{Hash: make(data.SparseBytes, 4), Sig: data.SparseBytes{0x00, 0x01}}
+	out, err := json.Marshal(in)
+	require.Nil(err)
+	assert.Equal(`{"hash":"","sig":"0001"}`, string(out))
+	var back SData
+	require.Nil(json.Unmarshal(out, &back))
+	assert.Equal(SData{Hash: data.SparseBytes{}, Sig: in.Sig}, back)
+
+	// omitempty only skips empty values
+	out, err = json.Marshal(SData{Hash: data.SparseBytes{0x01}, Sig: data.SparseBytes{0x00}})
+	require.Nil(err)
+	assert.Equal(`{"hash":"01","sig":""}`, string(out))
+	out, err = json.Marshal(SData{})
+	require.Nil(err)
+	assert.Equal(`{"hash":""}`, string(out))
+
+	// and decodes anything Bytes does
+	require.Nil(json.Unmarshal([]byte(`{"hash":[26,43]}`), &back))
+	assert.Equal(data.SparseBytes{0x1a, 0x2b}, back.Hash)
+	assert.NotNil(json.Unmarshal([]byte(`{"hash":"xyz"}`), &back))
+}
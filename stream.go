@@ -0,0 +1,137 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"math"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ReadBase64 decodes the url-safe, padded base64 text from r (the same
+// format as B64Encoder, without the json quotes), while it is read.
+//
+// It returns an error if the decoded value is more than max bytes, without
+// reading much further than that, or if max is negative. Newlines in the
+// input are ignored.
+func ReadBase64(r io.Reader, max int) (Bytes, error) {
+	if max < 0 {
+		return nil, errors.Errorf("Invalid maximum size %d", max)
+	}
+	cr := &countingReader{r: r}
+	res, err := readBase64(cr, max)
+	err = formatHook("base64", cr.n, res, err)
+	if err != nil {
+		return nil, err
+	}
+	return Bytes(res), nil
+}
+
+func readBase64(r io.Reader, max int) ([]byte, error) {
+	dec := base64.NewDecoder(base64.URLEncoding, r)
+	res, err := ioutil.ReadAll(limitOverMax(dec, max))
+	if err != nil {
+		return nil, errors.Wrap(err, "decode base64")
+	}
+	if len(res) > max {
+		return nil, errors.Errorf("Decoded value exceeds %d bytes", max)
+	}
+	return res, nil
+}
+
+// countingReader counts the bytes read from r, for the Observer
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+// limitOverMax limits r to one byte more than max, so that reading more
+// than max bytes can be detected, without overflowing for the largest max
+func limitOverMax(r io.Reader, max int) io.Reader {
+	n := int64(max)
+	if n < math.MaxInt64 {
+		n++
+	}
+	return io.LimitReader(r, n)
+}
+
+// decodeIntoBuffers holds the scratch buffers of DecodeInto
+var decodeIntoBuffers = sync.Pool{
+	New: func() interface{} { return new([]byte) },
+}
+
+// DecodeInto decodes encoded (a json string) with enc, and writes the raw
+// bytes to w, eg. a file or a hash.Hash. It returns the number of bytes
+// written.
+//
+// For the encoders that PooledDecoder decodes directly, the bytes go
+// through a pooled buffer, so there is no garbage in steady state. As
+// usual for an io.Writer, w must not keep the slice it is given.
+func DecodeInto(w io.Writer, encoded []byte, enc ByteEncoder) (int, error) {
+	bp := decodeIntoBuffers.Get().(*[]byte)
+	res, err := appendDecoded(enc, (*bp)[:0], encoded)
+	if err != nil {
+		decodeIntoBuffers.Put(bp)
+		return 0, err
+	}
+	n, err := w.Write(res)
+	if cap(res) <= maxPooledSize {
+		*bp = res[:0]
+		decodeIntoBuffers.Put(bp)
+	}
+	if err != nil {
+		return n, errors.Wrap(err, "write")
+	}
+	return n, nil
+}
+
+// NDJSONBytesDecoder reads newline-delimited json strings from a stream,
+// decoding each one with a ByteEncoder.
+//
+// Only one line is held in memory at a time, and lines longer than
+// bufio.MaxScanTokenSize are rejected.
+type NDJSONBytesDecoder struct {
+	scanner *bufio.Scanner
+	enc     ByteEncoder
+	line    int
+}
+
+// NewNDJSONBytesDecoder returns a decoder reading from r. Each line must
+// be a json string as produced by enc.Marshal.
+func NewNDJSONBytesDecoder(r io.Reader, enc ByteEncoder) *NDJSONBytesDecoder {
+	return &NDJSONBytesDecoder{
+		scanner: bufio.NewScanner(r),
+		enc:     enc,
+	}
+}
+
+// Next returns the next value in the stream, skipping blank lines.
+// It returns io.EOF once the stream is exhausted.
+func (d *NDJSONBytesDecoder) Next() (Bytes, error) {
+	for d.scanner.Scan() {
+		d.line++
+		line := bytes.TrimSpace(d.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var res []byte
+		if err := unmarshalHooked(d.enc, &res, line); err != nil {
+			return nil, errors.Wrapf(err, "line %d", d.line)
+		}
+		return Bytes(res), nil
+	}
+	if err := d.scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "line %d", d.line+1)
+	}
+	return nil, io.EOF
+}
@@ -0,0 +1,168 @@
+package data_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBase64(t *testing.T) {
+	assert := assert.New(t)
+
+	long := bytes.Repeat([]byte("D!.3s"), 1000)
+	longEnc, err := data.B64Encoder.Marshal(long)
+	assert.Nil(err, "%+v", err)
+	longStr := strings.Trim(string(longEnc), `"`)
+
+	cases := []struct {
+		input    string
+		max      int
+		expected data.Bytes
+	}{
+		{"Zm9v", 10, data.Bytes("foo")},
+		{"RCEuM3M=", 5, data.Bytes("D!.3s")},
+		{"D4_a--1=", 5, data.Bytes{0x0f, 0x8f, 0xda, 0xfb, 0xed}},
+		{"", 0, data.Bytes{}},
+		{longStr, len(long), data.Bytes(long)},
+		// the largest limit doesn't overflow
+		{"RCEuM3M=", int(^uint(0) >> 1), data.Bytes("D!.3s")},
+		// newlines are ignored
+		{"RCEu\nM3M=\n", 5, data.Bytes("D!.3s")},
+		// over the limit
+		{"RCEuM3M=", 4, nil},
+		{longStr, 100, nil},
+		// malformed stream
+		{"D4/a++1=", 10, nil},
+		{"hey!", 10, nil},
+		{"abc", 10, nil},
+		// invalid limit
+		{"Zm9v", -1, nil},
+	}
+
+	for i, tc := range cases {
+		res, err := data.ReadBase64(strings.NewReader(tc.input), tc.max)
+		if tc.expected == nil {
+			assert.NotNil(err, "%d", i)
+		} else if assert.Nil(err, "%d: %+v", i, err) {
+			assert.Equal(tc.expected, res, "%d", i)
+		}
+	}
+}
+
+func TestNDJSONBytesDecoder(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		input    string
+		expected []data.Bytes
+		// line of the malformed value, 0 if the stream is valid
+		badLine int
+	}{
+		{"", nil, 0},
+		{`"1A2B"`, []data.Bytes{{0x1a, 0x2b}}, 0},
+		{"\"1A2B\"\n\"3C4D\"\n", []data.Bytes{{0x1a, 0x2b}, {0x3c, 0x4d}}, 0},
+		{"\"\"\n\"00\"", []data.Bytes{{}, {0x00}}, 0},
+		// blank lines are skipped
+		{"\n\"1A2B\"\n\n  \n\"3C4D\"\r\n\n", []data.Bytes{{0x1a, 0x2b}, {0x3c, 0x4d}}, 0},
+		// malformed lines
+		{"\"1A2B\"\n\"3C4\"\n\"5E6F\"\n", []data.Bytes{{0x1a, 0x2b}}, 2},
+		{"\"1A2B\"\n\n1A2B\n", []data.Bytes{{0x1a, 0x2b}}, 3},
+		{`["1A2B"]`, nil, 1},
+	}
+
+	for i, tc := range cases {
+		dec := data.NewNDJSONBytesDecoder(strings.NewReader(tc.input), data.HexEncoder)
+		var res []data.Bytes
+		var err error
+		for {
+			var b data.Bytes
+			b, err = dec.Next()
+			if err != nil {
+				break
+			}
+			res = append(res, b)
+		}
+		assert.Equal(tc.expected, res, "%d", i)
+		if tc.badLine == 0 {
+			assert.Equal(io.EOF, err, "%d", i)
+		} else if assert.NotEqual(io.EOF, err, "%d", i) {
+			assert.Contains(err.Error(), fmt.Sprintf("line %d", tc.badLine), "%d", i)
+		}
+	}
+}
+
+// failingWriter accepts limit bytes, and fails after that
+type failingWriter struct {
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		n := w.limit
+		w.limit = 0
+		return n, errors.New("disk full")
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestDecodeInto(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	long := []byte(strings.Repeat("a longer value ", 50))
+	cases := []struct {
+		encoder data.ByteEncoder
+		input   []byte
+	}{
+		{data.HexEncoder, []byte("D!.3s")},
+		{data.HexFormat{Separator: " ", GroupSize: 2}, long},
+		{data.B64Encoder, long},
+		{data.RawB64Encoder, []byte{}},
+		{data.PercentEncoder, []byte("D!.3s")},
+	}
+
+	for i, tc := range cases {
+		encoded, err := tc.encoder.Marshal(tc.input)
+		require.Nil(err, "%+v", err)
+
+		// into a buffer
+		var buf bytes.Buffer
+		buf.WriteString("prefix:")
+		n, err := data.DecodeInto(&buf, encoded, tc.encoder)
+		if assert.Nil(err, "%d: %+v", i, err) {
+			assert.Equal(len(tc.input), n, "%d", i)
+			assert.Equal("prefix:"+string(tc.input), buf.String(), "%d", i)
+		}
+
+		// and into a hash
+		h := sha256.New()
+		n, err = data.DecodeInto(h, encoded, tc.encoder)
+		if assert.Nil(err, "%d: %+v", i, err) {
+			assert.Equal(len(tc.input), n, "%d", i)
+			expected := sha256.Sum256(tc.input)
+			assert.Equal(expected[:], h.Sum(nil), "%d", i)
+		}
+	}
+
+	// invalid input writes nothing
+	var buf bytes.Buffer
+	n, err := data.DecodeInto(&buf, []byte(`"1A2"`), data.HexEncoder)
+	assert.NotNil(err)
+	assert.Equal(0, n)
+	assert.Equal(0, buf.Len())
+
+	// write errors are returned with the count
+	n, err = data.DecodeInto(&failingWriter{limit: 2}, []byte(`"1A2B3C"`), data.HexEncoder)
+	if assert.NotNil(err) {
+		assert.Contains(err.Error(), "disk full")
+	}
+	assert.Equal(2, n)
+}
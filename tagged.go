@@ -0,0 +1,50 @@
+package data
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// TaggedBytes is a byte slice that records its encoding in the json, like:
+//
+//	{"enc": "hex", "val": "1A2B"}
+//
+// It is marshaled with the current Encoder, which must be registered (see
+// RegisterEncoder), and unmarshaled with whichever encoder is named in enc,
+// no matter what the current Encoder is. This keeps stored blobs readable
+// after changing the default encoding.
+type TaggedBytes []byte
+
+// taggedEnv is the json layout of TaggedBytes
+type taggedEnv struct {
+	Enc string          `json:"enc"`
+	Val json.RawMessage `json:"val"`
+}
+
+func (t TaggedBytes) MarshalJSON() ([]byte, error) {
+	enc := currentEncoder()
+	name, ok := EncoderName(enc)
+	if !ok {
+		return nil, errors.Errorf("Encoder is not registered: %#v", enc)
+	}
+	val, err := marshalObserved(enc, t)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(taggedEnv{Enc: name, Val: val})
+}
+
+func (t *TaggedBytes) UnmarshalJSON(data []byte) error {
+	var env taggedEnv
+	err := json.Unmarshal(data, &env)
+	if err != nil {
+		return errors.Wrap(err, "parse tagged bytes")
+	}
+	enc, ok := EncoderByName(env.Enc)
+	if !ok {
+		return errors.Errorf("Unknown encoder: %s", env.Enc)
+	}
+	ref := (*[]byte)(t)
+	return unmarshalHooked(enc, ref, env.Val)
+}
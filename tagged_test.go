@@ -0,0 +1,70 @@
+package data_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TData struct {
+	Count int              `json:"count"`
+	Data  data.TaggedBytes `json:"data"`
+}
+
+func TestTaggedBytes(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	in := TData{Count: 3, Data: data.TaggedBytes("D!.3s")}
+	cases := []struct {
+		encoder  data.ByteEncoder
+		expected string
+	}{
+		{data.HexEncoder, `{"count":3,"data":{"enc":"hex","val":"44212E3373"}}`},
+		{data.B64Encoder, `{"count":3,"data":{"enc":"base64","val":"RCEuM3M="}}`},
+		{data.RawB64Encoder, `{"count":3,"data":{"enc":"rawbase64","val":"RCEuM3M"}}`},
+	}
+
+	for _, tc := range cases {
+		data.Encoder = tc.encoder
+		d, err := json.Marshal(in)
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.expected, string(d))
+
+		// decodes with any default encoder
+		for _, def := range []data.ByteEncoder{data.HexEncoder, data.B64Encoder} {
+			data.Encoder = def
+			out := TData{}
+			err = json.Unmarshal(d, &out)
+			require.Nil(err, "%+v", err)
+			assert.Equal(in, out)
+		}
+	}
+
+	// written as hex, read with a base64 default
+	data.Encoder = data.B64Encoder
+	out := TData{}
+	err := json.Unmarshal([]byte(`{"count":1,"data":{"enc":"hex","val":"cafe"}}`), &out)
+	require.Nil(err, "%+v", err)
+	assert.Equal(data.TaggedBytes{0xca, 0xfe}, out.Data)
+
+	// errors
+	errs := []string{
+		`{"count":1,"data":{"enc":"unknown","val":"cafe"}}`,
+		`{"count":1,"data":{"val":"cafe"}}`,
+		`{"count":1,"data":{"enc":"hex","val":"caf"}}`,
+		`{"count":1,"data":"cafe"}`,
+	}
+	for _, input := range errs {
+		err := json.Unmarshal([]byte(input), &out)
+		assert.NotNil(err, input)
+	}
+
+	// can only marshal with a registered encoder
+	data.Encoder = data.HexFormat{Lower: true, Prefix: "0x"}
+	_, err = json.Marshal(in)
+	assert.NotNil(err)
+	data.Encoder = data.HexEncoder
+}
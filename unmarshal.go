@@ -0,0 +1,167 @@
+package data
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// BytesValidator can be implemented by types that are decoded with
+// DecodeBytes, to check the decoded bytes before they are assigned.
+type BytesValidator interface {
+	ValidateBytes(b []byte) error
+}
+
+// validatorBox lets atomic.Value hold a nil validator
+type validatorBox struct {
+	fn func([]byte) error
+}
+
+var globalValidator atomic.Value
+
+// SetGlobalValidator sets a function that checks every value decoded by
+// this package, after it is decoded and before it is assigned. That is
+// everything the unmarshal methods of its byte slice types (Bytes,
+// URLSafeBytes, CappedBytes and so on) decode, and everything its package
+// level functions and decoders read from an encoded form, be it text like
+// DecodeBytes or ParseHexDump, or binary like UnmarshalWire. If it
+// returns an error, the decode fails with that error, and the value is
+// left untouched. This allows process wide policies, like rejecting a
+// blocklist of known bad keys:
+//
+//	data.SetGlobalValidator(func(b []byte) error {
+//		if blocked[string(b)] {
+//			return errors.New("blocked key")
+//		}
+//		return nil
+//	})
+//
+// fn is called on the decoding goroutine, so it must be safe for
+// concurrent use, and must not keep or modify b. Not checked are
+// conversions from other go types, like BytesFromIP, and calling the
+// Unmarshal method of a ByteEncoder directly (which the wrapping encoders,
+// like NewFallbackEncoder, do with their inner ones). nil (the default)
+// removes the validator, with no overhead beyond checking for one.
+func SetGlobalValidator(fn func([]byte) error) {
+	globalValidator.Store(validatorBox{fn})
+}
+
+// currentValidator returns the global validator, or nil
+func currentValidator() func([]byte) error {
+	box, _ := globalValidator.Load().(validatorBox)
+	return box.fn
+}
+
+// DecodeBytes decodes src with enc into dst, which must be a pointer to a
+// byte slice type (like *Bytes, or *T with type T []byte).
+//
+// If dst implements BytesValidator, ValidateBytes is called after decoding,
+// and any error aborts the unmarshal, leaving dst unchanged. This lets a
+// custom type validate its content with a one-line UnmarshalJSON:
+//
+//	func (m *Magic) UnmarshalJSON(d []byte) error {
+//	  return data.DecodeBytes(m, d, data.Encoder)
+//	}
+func DecodeBytes(dst interface{}, src []byte, enc ByteEncoder) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() ||
+		v.Elem().Kind() != reflect.Slice || v.Elem().Type().Elem().Kind() != reflect.Uint8 {
+		return errors.Errorf("Cannot decode bytes into %T", dst)
+	}
+	var res []byte
+	err := unmarshalHooked(enc, &res, src)
+	if err != nil {
+		return err
+	}
+	if val, ok := dst.(BytesValidator); ok {
+		err = val.ValidateBytes(res)
+		if err != nil {
+			return err
+		}
+	}
+	v.Elem().SetBytes(res)
+	return nil
+}
+
+// UnmarshalOneOfLen decodes in with enc, and only sets dst if the decoded
+// value has one of the allowed lengths. Otherwise it returns an error and
+// leaves dst untouched.
+func UnmarshalOneOfLen(dst *Bytes, in []byte, enc ByteEncoder, allowed ...int) error {
+	var res []byte
+	err := unmarshalHooked(enc, &res, in)
+	if err != nil {
+		return err
+	}
+	for _, n := range allowed {
+		if len(res) == n {
+			*dst = res
+			return nil
+		}
+	}
+	return errors.Errorf("Invalid length %d, expected one of %v", len(res), allowed)
+}
+
+// UnmarshalBounded decodes in with enc, and only sets dst if the decoded
+// value has between min and max bytes (inclusive). Otherwise it returns a
+// TooShortError or TooLongError and leaves dst untouched.
+func UnmarshalBounded(dst *Bytes, in []byte, enc ByteEncoder, min, max int) error {
+	var res []byte
+	err := unmarshalHooked(enc, &res, in)
+	if err != nil {
+		return err
+	}
+	if len(res) < min {
+		return errors.WithStack(TooShortError{Len: len(res), Min: min})
+	}
+	if len(res) > max {
+		return errors.WithStack(TooLongError{Len: len(res), Max: max})
+	}
+	*dst = res
+	return nil
+}
+
+// UnmarshalCanonical decodes in with enc into dst, and also returns the
+// canonical encoding of the value: the text that enc.Marshal produces
+// (without quotes). This is useful as a cache key, as eg. hex in any case
+// or base64 with or without padding (where accepted) all map to one form.
+//
+// On error, dst is untouched.
+func UnmarshalCanonical(dst *Bytes, in []byte, enc ByteEncoder) (canonical string, err error) {
+	var res []byte
+	err = unmarshalHooked(enc, &res, in)
+	if err != nil {
+		return "", err
+	}
+	d, err := enc.Marshal(res)
+	if err != nil {
+		return "", err
+	}
+	canonical, err = ParseJSONString(d)
+	if err != nil {
+		return "", err
+	}
+	*dst = res
+	return canonical, nil
+}
+
+// DecodeAll decodes every item with enc, without stopping at the first
+// error, so all bad items can be reported at once. Both results have the
+// same length as items: res[i] is the decoded item i, or nil if errs[i] is
+// set (and errs[i] is nil otherwise). The errors are FieldErrors labeled
+// with the index, like "[3]".
+func DecodeAll(items [][]byte, enc ByteEncoder) (res []Bytes, errs []error) {
+	res = make([]Bytes, len(items))
+	errs = make([]error, len(items))
+	for i, item := range items {
+		var b []byte
+		err := unmarshalHooked(enc, &b, item)
+		if err != nil {
+			errs[i] = WrapFieldError(fmt.Sprintf("[%d]", i), err)
+			continue
+		}
+		res[i] = b
+	}
+	return res, errs
+}
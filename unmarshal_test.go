@@ -0,0 +1,405 @@
+package data_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalOneOfLen(t *testing.T) {
+	assert := assert.New(t)
+
+	hex := data.HexEncoder
+	cases := []struct {
+		input    string
+		allowed  []int
+		expected data.Bytes
+	}{
+		{`"1a2b"`, []int{2}, data.Bytes{0x1a, 0x2b}},
+		{`"1a2b3c"`, []int{2, 3}, data.Bytes{0x1a, 0x2b, 0x3c}},
+		{`""`, []int{0, 20}, data.Bytes{}},
+		// rejected lengths
+		{`"1a2b3c"`, []int{2, 4}, nil},
+		{`"1a"`, []int{}, nil},
+		// invalid input
+		{`"1a2"`, []int{2}, nil},
+	}
+
+	for _, tc := range cases {
+		dst := data.Bytes("keep")
+		err := data.UnmarshalOneOfLen(&dst, []byte(tc.input), hex, tc.allowed...)
+		if tc.expected == nil {
+			assert.NotNil(err, tc.input)
+			// destination is untouched
+			assert.Equal(data.Bytes("keep"), dst, tc.input)
+		} else if assert.Nil(err, "%s: %+v", tc.input, err) {
+			assert.Equal(tc.expected, dst, tc.input)
+		}
+	}
+}
+
+// Magic must start with the magic header
+type Magic []byte
+
+var errNoMagic = errors.New("missing magic header")
+
+func (m *Magic) ValidateBytes(b []byte) error {
+	if !bytes.HasPrefix(b, []byte{0xca, 0xfe}) {
+		return errNoMagic
+	}
+	return nil
+}
+
+func (m *Magic) UnmarshalJSON(d []byte) error {
+	return data.DecodeBytes(m, d, data.HexEncoder)
+}
+
+type MagicData struct {
+	Name  string `json:"name"`
+	Magic Magic  `json:"magic"`
+}
+
+func TestDecodeBytes(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cases := []struct {
+		input    string
+		expected Magic
+		err      error
+	}{
+		{`{"name":"ok","magic":"CAFE0102"}`, Magic{0xca, 0xfe, 0x01, 0x02}, nil},
+		{`{"name":"ok","magic":"cafe"}`, Magic{0xca, 0xfe}, nil},
+		{`{"name":"bad","magic":"C0FFEE"}`, nil, errNoMagic},
+		{`{"name":"bad","magic":""}`, nil, errNoMagic},
+		// decoding errors from the encoder
+		{`{"name":"bad","magic":"CAFE0"}`, nil, nil},
+	}
+
+	for _, tc := range cases {
+		parsed := MagicData{Magic: Magic("keep")}
+		err := json.Unmarshal([]byte(tc.input), &parsed)
+		if tc.expected == nil {
+			require.NotNil(err, tc.input)
+			if tc.err != nil {
+				assert.Equal(tc.err, err, tc.input)
+			}
+			// destination is untouched
+			assert.Equal(Magic("keep"), parsed.Magic, tc.input)
+		} else if assert.Nil(err, "%s: %+v", tc.input, err) {
+			assert.Equal(tc.expected, parsed.Magic, tc.input)
+		}
+	}
+
+	// also works for types without a validator
+	var b data.Bytes
+	err := data.DecodeBytes(&b, []byte(`"AB"`), data.HexEncoder)
+	require.Nil(err, "%+v", err)
+	assert.Equal(data.Bytes{0xab}, b)
+
+	// only into pointers to byte slices
+	var s string
+	assert.NotNil(data.DecodeBytes(&s, []byte(`"AB"`), data.HexEncoder))
+	assert.NotNil(data.DecodeBytes(b, []byte(`"AB"`), data.HexEncoder))
+	assert.NotNil(data.DecodeBytes((*data.Bytes)(nil), []byte(`"AB"`), data.HexEncoder))
+}
+
+func TestUnmarshalCanonical(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cases := []struct {
+		encoder   data.ByteEncoder
+		input     string
+		canonical string
+	}{
+		{data.HexEncoder, `"1A2B"`, "1A2B"},
+		// not canonical
+		{data.HexEncoder, `"1a2b"`, "1A2B"},
+		{data.HexFormat{Lower: true}, `"1A2b"`, "1a2b"},
+		{data.HexFormat{Prefix: "0x"}, `"1a2b"`, "0x1A2B"},
+		{data.B64Encoder, `"RCEuM3M="`, "RCEuM3M="},
+		{data.RawB64Encoder, `"RCEuM3M"`, "RCEuM3M"},
+		{data.PercentEncoder, `"a b%2fc"`, "a%20b%2Fc"},
+	}
+
+	for _, tc := range cases {
+		var dst data.Bytes
+		canonical, err := data.UnmarshalCanonical(&dst, []byte(tc.input), tc.encoder)
+		require.Nil(err, "%s: %+v", tc.input, err)
+		assert.Equal(tc.canonical, canonical, tc.input)
+
+		// matches the Marshal output
+		d, err := tc.encoder.Marshal(dst)
+		require.Nil(err, "%+v", err)
+		assert.Equal(`"`+canonical+`"`, string(d))
+	}
+
+	dst := data.Bytes("keep")
+	_, err := data.UnmarshalCanonical(&dst, []byte(`"1a2"`), data.HexEncoder)
+	assert.NotNil(err)
+	assert.Equal(data.Bytes("keep"), dst)
+}
+
+func TestUnmarshalBounded(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		input    string
+		min, max int
+		expected data.Bytes
+		tooShort bool
+		tooLong  bool
+	}{
+		// in range
+		{`"1A2B"`, 1, 4, data.Bytes{0x1a, 0x2b}, false, false},
+		{`"1A"`, 1, 4, data.Bytes{0x1a}, false, false},
+		{`"1A2B3C4D"`, 1, 4, data.Bytes{0x1a, 0x2b, 0x3c, 0x4d}, false, false},
+		{`""`, 0, 4, data.Bytes{}, false, false},
+		// too short
+		{`""`, 1, 4, nil, true, false},
+		{`"1A2B"`, 3, 4, nil, true, false},
+		// too long
+		{`"1A2B3C4D5E"`, 1, 4, nil, false, true},
+		{`"1A"`, 0, 0, nil, false, true},
+		// exact length
+		{`"1A2B"`, 2, 2, data.Bytes{0x1a, 0x2b}, false, false},
+		{`"1A"`, 2, 2, nil, true, false},
+		{`"1A2B3C"`, 2, 2, nil, false, true},
+		// invalid encoding
+		{`"1A2"`, 0, 4, nil, false, false},
+	}
+
+	for _, tc := range cases {
+		orig := data.Bytes("orig")
+		dst := orig
+		err := data.UnmarshalBounded(&dst, []byte(tc.input), data.HexEncoder, tc.min, tc.max)
+		if tc.expected != nil {
+			assert.Nil(err, "%s: %+v", tc.input, err)
+			assert.Equal(tc.expected, dst, tc.input)
+			continue
+		}
+		if assert.NotNil(err, tc.input) {
+			var short data.TooShortError
+			var long data.TooLongError
+			assert.Equal(tc.tooShort, errors.As(err, &short), tc.input)
+			assert.Equal(tc.tooLong, errors.As(err, &long), tc.input)
+		}
+		assert.Equal(orig, dst, tc.input)
+	}
+
+	err := data.UnmarshalBounded(new(data.Bytes), []byte(`"1A2B3C"`), data.HexEncoder, 0, 2)
+	var long data.TooLongError
+	if assert.True(errors.As(err, &long)) {
+		assert.Equal(data.TooLongError{Len: 3, Max: 2}, long)
+	}
+}
+
+func TestDecodeAll(t *testing.T) {
+	assert := assert.New(t)
+
+	items := [][]byte{
+		[]byte(`"1A2B"`),
+		[]byte(`"1A2"`),
+		[]byte(`""`),
+		[]byte(`123`),
+		[]byte(`"FF"`),
+		[]byte(`"XY"`),
+	}
+	res, errs := data.DecodeAll(items, data.HexEncoder)
+	if !assert.Len(res, len(items)) || !assert.Len(errs, len(items)) {
+		return
+	}
+
+	expected := []data.Bytes{{0x1a, 0x2b}, nil, {}, nil, {0xff}, nil}
+	for i := range items {
+		assert.Equal(expected[i], res[i], "%d", i)
+		if expected[i] != nil {
+			assert.Nil(errs[i], "%d", i)
+			continue
+		}
+		if assert.NotNil(errs[i], "%d", i) {
+			var fe data.FieldError
+			if assert.True(errors.As(errs[i], &fe), "%d", i) {
+				assert.Equal(fmt.Sprintf("[%d]", i), fe.Field)
+			}
+		}
+	}
+	// the original errors are still there
+	var ns data.NotStringError
+	assert.True(errors.As(errs[3], &ns))
+	assert.Equal("number", ns.Kind)
+
+	// all good, or nothing at all
+	res, errs = data.DecodeAll([][]byte{[]byte(`"01"`)}, data.HexEncoder)
+	assert.Equal([]data.Bytes{{0x01}}, res)
+	assert.Equal([]error{nil}, errs)
+	res, errs = data.DecodeAll(nil, data.HexEncoder)
+	assert.Empty(res)
+	assert.Empty(errs)
+}
+
+func TestGlobalValidator(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	defer data.SaveEncoder()()
+	defer data.SetGlobalValidator(nil)
+	data.Encoder = data.HexEncoder
+
+	errBlocked := errors.New("blocked value")
+	var calls int
+	data.SetGlobalValidator(func(b []byte) error {
+		calls++
+		// DEAD, or a uuid starting with it
+		if bytes.Equal(b, []byte{0xde, 0xad}) || len(b) == 16 && bytes.HasPrefix(b, []byte{0xde, 0xad}) {
+			return errBlocked
+		}
+		return nil
+	})
+
+	cases := []struct {
+		input   string
+		blocked bool
+	}{
+		{`"DEAD"`, true},
+		{`"dead"`, true},
+		{`[222, 173]`, true},
+		{`"DEADBEEF"`, false},
+		{`"1A2B"`, false},
+		{`""`, false},
+	}
+
+	for _, tc := range cases {
+		b := data.Bytes("untouched")
+		err := json.Unmarshal([]byte(tc.input), &b)
+		if tc.blocked {
+			assert.True(errors.Is(err, errBlocked), "%s: %+v", tc.input, err)
+			assert.Equal(data.Bytes("untouched"), b, tc.input)
+		} else if assert.Nil(err, "%s: %+v", tc.input, err) {
+			assert.NotEqual(data.Bytes("untouched"), b, tc.input)
+		}
+	}
+	assert.Equal(len(cases), calls)
+
+	// the other wrapper types are validated too
+	var sparse data.SparseBytes
+	assert.True(errors.Is(json.Unmarshal([]byte(`"DEAD"`), &sparse), errBlocked))
+	var tagged data.TaggedBytes
+	assert.True(errors.Is(json.Unmarshal([]byte(`{"enc":"base64","val":"3q0="}`), &tagged), errBlocked))
+	capped := data.NewCappedBytes(4)
+	assert.True(errors.Is(json.Unmarshal([]byte(`"DEAD"`), &capped), errBlocked))
+
+	// invalid input doesn't get to the validator
+	calls = 0
+	var b data.Bytes
+	assert.NotNil(json.Unmarshal([]byte(`"DEA"`), &b))
+	assert.Equal(0, calls)
+
+	// as are the decode helpers
+	src := []byte(`"DEAD"`)
+	helpers := map[string]func() error{
+		"DecodeBytes": func() error {
+			return data.DecodeBytes(&b, src, data.HexEncoder)
+		},
+		"UnmarshalOneOfLen": func() error {
+			return data.UnmarshalOneOfLen(&b, src, data.HexEncoder, 2)
+		},
+		"UnmarshalBounded": func() error {
+			return data.UnmarshalBounded(&b, src, data.HexEncoder, 0, 4)
+		},
+		"UnmarshalCanonical": func() error {
+			_, err := data.UnmarshalCanonical(&b, src, data.HexEncoder)
+			return err
+		},
+		"DecodeAll": func() error {
+			_, errs := data.DecodeAll([][]byte{src}, data.HexEncoder)
+			return errs[0]
+		},
+		"DecodeInto": func() error {
+			_, err := data.DecodeInto(ioutil.Discard, src, data.HexEncoder)
+			return err
+		},
+		"DecodeInto fallback": func() error {
+			_, err := data.DecodeInto(ioutil.Discard, src, data.WithCopyOutput(data.HexEncoder))
+			return err
+		},
+		"PooledDecoder": func() error {
+			_, err := data.NewPooledDecoder(data.HexEncoder).Decode(src)
+			return err
+		},
+		"NDJSONBytesDecoder": func() error {
+			_, err := data.NewNDJSONBytesDecoder(bytes.NewReader(src), data.HexEncoder).Next()
+			return err
+		},
+		"BytesFromEnv": func() error {
+			os.Setenv("DATA_GO_TEST_VALIDATOR", "dead")
+			defer os.Unsetenv("DATA_GO_TEST_VALIDATOR")
+			_, err := data.BytesFromEnv("DATA_GO_TEST_VALIDATOR")
+			return err
+		},
+		// and the other formats
+		"URLSafeBytes": func() error {
+			var u data.URLSafeBytes
+			return json.Unmarshal([]byte(`"3q0"`), &u)
+		},
+		"ReadBase64": func() error {
+			_, err := data.ReadBase64(strings.NewReader("3q0="), 10)
+			return err
+		},
+		"UnmarshalBinary": func() error {
+			return b.UnmarshalBinary([]byte{0xde, 0xad})
+		},
+		"BytesFromUUID": func() error {
+			_, err := data.BytesFromUUID("dead0000-0000-0000-0000-000000000000")
+			return err
+		},
+		"ParseHexDump": func() error {
+			_, err := data.ParseHexDump("00000000  de ad\n00000002\n")
+			return err
+		},
+		"BytesFromDecimal": func() error {
+			_, err := data.BytesFromDecimal("570.05", 2)
+			return err
+		},
+		"SplitLengthPrefixedB64": func() error {
+			enc, err := data.LengthPrefixedB64Encoder.Marshal([]byte{0xde, 0xad})
+			if err != nil {
+				return err
+			}
+			_, err = data.SplitLengthPrefixedB64(enc)
+			return err
+		},
+		"UnmarshalWire": func() error {
+			_, _, err := data.UnmarshalWire([]byte{2, 0xde, 0xad})
+			return err
+		},
+		"UnmarshalBytesSlice": func() error {
+			_, err := data.UnmarshalBytesSlice([]byte{1, 2, 0xde, 0xad})
+			return err
+		},
+		"Discriminated": func() error {
+			_, _, err := data.Discriminated.Unmarshal(data.Bytes{7, 0xde, 0xad})
+			return err
+		},
+	}
+	for name, fn := range helpers {
+		b = data.Bytes("untouched")
+		err := fn()
+		assert.True(errors.Is(err, errBlocked), "%s: %+v", name, err)
+		assert.Equal(data.Bytes("untouched"), b, name)
+	}
+
+	// and nil removes it
+	calls = 0
+	data.SetGlobalValidator(nil)
+	require.Nil(json.Unmarshal([]byte(`"DEAD"`), &b))
+	assert.Equal(data.Bytes{0xde, 0xad}, b)
+	assert.Equal(0, calls)
+}
@@ -0,0 +1,65 @@
+package data
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// URLSafeBytes is a byte slice for use in urls: query parameters, path
+// segments or tokens in links. It always uses url-safe base64 without
+// padding (like RawB64Encoder), independent of the global Encoder, so the
+// text never needs escaping.
+//
+// For parsing it also accepts padded input (as produced by B64Encoder),
+// since both forms are commonly found in the wild.
+//
+// It implements encoding.TextMarshaler, so it can be used directly as a
+// query or form value, as well as in json.
+type URLSafeBytes []byte
+
+func (u URLSafeBytes) String() string {
+	return base64.RawURLEncoding.EncodeToString(u)
+}
+
+func (u URLSafeBytes) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *URLSafeBytes) UnmarshalText(text []byte) error {
+	res, err := decodeURLSafe(string(text))
+	err = formatHook("urlsafe", len(text), res, err)
+	if err != nil {
+		return err
+	}
+	*u = res
+	return nil
+}
+
+func (u URLSafeBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.String())
+}
+
+func (u *URLSafeBytes) UnmarshalJSON(data []byte) error {
+	s, err := ParseJSONString(data)
+	if err != nil {
+		return err
+	}
+	return u.UnmarshalText([]byte(s))
+}
+
+// decodeURLSafe decodes url-safe base64 with optional padding. If there is
+// padding, it must be exactly what B64Encoder would add.
+func decodeURLSafe(s string) ([]byte, error) {
+	raw := strings.TrimRight(s, "=")
+	if pad := len(s) - len(raw); pad > 0 && (len(s)%4 != 0 || pad != (4-len(raw)%4)%4) {
+		return nil, errors.Errorf("Invalid padding in url-safe base64: %s", s)
+	}
+	res, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode url-safe base64")
+	}
+	return res, nil
+}
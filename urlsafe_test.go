@@ -0,0 +1,102 @@
+package data_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLSafeBytes(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cases := []struct {
+		bytes    data.URLSafeBytes
+		expected string
+	}{
+		{data.URLSafeBytes{}, ""},
+		{data.URLSafeBytes("f"), "Zg"},
+		{data.URLSafeBytes("fo"), "Zm8"},
+		{data.URLSafeBytes("foo"), "Zm9v"},
+		{data.URLSafeBytes{0xfb, 0xff, 0xbf}, "-_-_"},
+		{data.URLSafeBytes{0x0f, 0x8f, 0xda, 0xfb, 0xed}, "D4_a--0"},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(tc.expected, tc.bytes.String())
+
+		// query string round trip, without escaping
+		q := url.Values{}
+		q.Set("key", tc.bytes.String())
+		assert.Equal("key="+tc.expected, q.Encode())
+		parsed, err := url.ParseQuery(q.Encode())
+		require.Nil(err, "%+v", err)
+		var res data.URLSafeBytes
+		err = res.UnmarshalText([]byte(parsed.Get("key")))
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.bytes, res)
+
+		// json round trip
+		js, err := json.Marshal(tc.bytes)
+		require.Nil(err, "%+v", err)
+		assert.Equal(`"`+tc.expected+`"`, string(js))
+		var back data.URLSafeBytes
+		err = json.Unmarshal(js, &back)
+		require.Nil(err, "%+v", err)
+		assert.Equal(tc.bytes, back)
+	}
+
+	// not affected by the global encoder
+	defer data.SaveEncoder()()
+	data.Encoder = data.HexEncoder
+	js, err := json.Marshal(data.URLSafeBytes("foo"))
+	require.Nil(err, "%+v", err)
+	assert.Equal(`"Zm9v"`, string(js))
+}
+
+func TestURLSafeBytesUnmarshal(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		input    string
+		expected data.URLSafeBytes
+	}{
+		// padding is optional
+		{"Zg", data.URLSafeBytes("f")},
+		{"Zg==", data.URLSafeBytes("f")},
+		{"Zm8", data.URLSafeBytes("fo")},
+		{"Zm8=", data.URLSafeBytes("fo")},
+		{"D4_a--0", data.URLSafeBytes{0x0f, 0x8f, 0xda, 0xfb, 0xed}},
+		{"D4_a--0=", data.URLSafeBytes{0x0f, 0x8f, 0xda, 0xfb, 0xed}},
+		{"", data.URLSafeBytes{}},
+		// std alphabet and garbage are rejected
+		{"D4/a++0", nil},
+		{"Z", nil},
+		{"Zm9v!", nil},
+		// padding must be right, if present
+		{"Zm8=====", nil},
+		{"Zm8==", nil},
+		{"Zm9v=", nil},
+		{"Zg=", nil},
+		{"=", nil},
+	}
+
+	for _, tc := range cases {
+		var res data.URLSafeBytes
+		err := res.UnmarshalText([]byte(tc.input))
+		if tc.expected == nil {
+			assert.NotNil(err, tc.input)
+		} else if assert.Nil(err, "%s: %+v", tc.input, err) {
+			assert.Equal(tc.expected, res, tc.input)
+		}
+	}
+
+	// json must be a string
+	var res data.URLSafeBytes
+	assert.NotNil(json.Unmarshal([]byte(`123`), &res))
+	assert.Nil(json.Unmarshal([]byte(`"Zm8="`), &res))
+	assert.Equal(data.URLSafeBytes("fo"), res)
+}
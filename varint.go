@@ -0,0 +1,118 @@
+package data
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// MarshalWire returns a compact, self-delimiting binary form of the bytes:
+// the length as an unsigned varint, followed by the raw bytes.
+func (b Bytes) MarshalWire() []byte {
+	res := make([]byte, binary.MaxVarintLen64+len(b))
+	n := binary.PutUvarint(res, uint64(len(b)))
+	n += copy(res[n:], b)
+	return res[:n]
+}
+
+// UnmarshalWire parses the output of MarshalWire from the start of buf.
+//
+// It returns the value and the number of bytes of buf consumed, so the next
+// value starts at buf[n:]. The value is a copy, not a slice of buf.
+func UnmarshalWire(buf []byte) (Bytes, int, error) {
+	start, end, err := wireValue(buf)
+	inLen := end
+	if err != nil {
+		inLen = len(buf)
+	}
+	err = formatHook("wire", inLen, buf[start:end], err)
+	if err != nil {
+		return nil, 0, err
+	}
+	return append(Bytes{}, buf[start:end]...), end, nil
+}
+
+// wireValue returns the bounds of the value at the start of buf, in the
+// format of MarshalWire
+func wireValue(buf []byte) (start, end int, err error) {
+	size, n := binary.Uvarint(buf)
+	if n == 0 {
+		return 0, 0, errors.New("Truncated length prefix")
+	}
+	if n < 0 {
+		return 0, 0, errors.New("Length prefix overflows uint64")
+	}
+	if size > uint64(len(buf)-n) {
+		return 0, 0, errors.Errorf("Truncated value: need %d bytes, have %d", size, len(buf)-n)
+	}
+	return n, n + int(size), nil
+}
+
+// Varint decodes the protobuf style unsigned varint at the start of b. It
+// returns the value and the number of bytes it took, so any data after it
+// starts at b[n:].
+//
+// Empty or truncated input (where the last byte still has the continuation
+// bit set) is an error, and so is a varint that doesn't fit in 64 bits.
+func (b Bytes) Varint() (uint64, int, error) {
+	v, n := binary.Uvarint(b)
+	if n == 0 {
+		return 0, 0, errors.New("Truncated varint")
+	}
+	if n < 0 {
+		return 0, 0, errors.New("Varint overflows uint64")
+	}
+	return v, n, nil
+}
+
+// AppendVarint appends v to b as a protobuf style unsigned varint, and
+// returns the result. Like the builtin append (and unlike Bytes.Append), it
+// reuses the spare capacity of b, so it can build a message in one buffer.
+func AppendVarint(b Bytes, v uint64) Bytes {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+// MarshalBytesSlice packs items into one binary blob: the number of items
+// as an unsigned varint, followed by each item as in MarshalWire.
+func MarshalBytesSlice(items []Bytes) []byte {
+	size := binary.MaxVarintLen64
+	for _, item := range items {
+		size += binary.MaxVarintLen64 + len(item)
+	}
+	res := make([]byte, size)
+	n := binary.PutUvarint(res, uint64(len(items)))
+	for _, item := range items {
+		n += binary.PutUvarint(res[n:], uint64(len(item)))
+		n += copy(res[n:], item)
+	}
+	return res[:n]
+}
+
+// UnmarshalBytesSlice parses the output of MarshalBytesSlice. The whole
+// buffer must be consumed, trailing bytes are an error.
+func UnmarshalBytesSlice(buf []byte) ([]Bytes, error) {
+	count, n := binary.Uvarint(buf)
+	if n <= 0 {
+		return nil, errors.New("Invalid item count")
+	}
+	buf = buf[n:]
+	// every item takes at least one byte, don't trust the count further
+	if count > uint64(len(buf)) {
+		return nil, errors.Errorf("Item count %d exceeds buffer", count)
+	}
+	res := make([]Bytes, 0, int(count))
+	for i := uint64(0); i < count; i++ {
+		item, n, err := UnmarshalWire(buf)
+		if err != nil {
+			return nil, errors.Wrapf(err, "item %d", i)
+		}
+		res = append(res, item)
+		buf = buf[n:]
+	}
+	if len(buf) > 0 {
+		return nil, errors.Errorf("%d trailing bytes", len(buf))
+	}
+	return res, nil
+}
@@ -0,0 +1,168 @@
+package data_test
+
+import (
+	"bytes"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWireCodec(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	large := data.Bytes(bytes.Repeat([]byte{0xab}, 300))
+	cases := []struct {
+		bytes  data.Bytes
+		prefix []byte
+	}{
+		{data.Bytes{}, []byte{0x00}},
+		{data.Bytes{0x1a, 0x2b}, []byte{0x02}},
+		// multi-byte length
+		{large, []byte{0xac, 0x02}},
+	}
+
+	var stream []byte
+	for i, tc := range cases {
+		d := tc.bytes.MarshalWire()
+		assert.Equal(tc.prefix, d[:len(tc.prefix)], "%d", i)
+		assert.Equal([]byte(tc.bytes), d[len(tc.prefix):], "%d", i)
+
+		res, n, err := data.UnmarshalWire(d)
+		require.Nil(err, "%d: %+v", i, err)
+		assert.Equal(len(d), n, "%d", i)
+		assert.Equal(tc.bytes, res, "%d", i)
+		stream = append(stream, d...)
+	}
+
+	// parse them all back from one stream
+	for i, tc := range cases {
+		res, n, err := data.UnmarshalWire(stream)
+		require.Nil(err, "%d: %+v", i, err)
+		assert.Equal(tc.bytes, res, "%d", i)
+		stream = stream[n:]
+	}
+	assert.Empty(stream)
+
+	// result doesn't alias the buffer
+	buf := data.Bytes{0x1a}.MarshalWire()
+	res, _, err := data.UnmarshalWire(buf)
+	require.Nil(err, "%+v", err)
+	buf[1] = 0xff
+	assert.Equal(data.Bytes{0x1a}, res)
+
+	errs := [][]byte{
+		nil,
+		{},
+		{0x02, 0x1a},                   // truncated value
+		{0xac, 0x02, 0x01},             // truncated value
+		{0x80},                         // truncated prefix
+		bytes.Repeat([]byte{0xff}, 11), // overflow
+	}
+	for i, input := range errs {
+		_, _, err := data.UnmarshalWire(input)
+		assert.NotNil(err, "%d", i)
+	}
+}
+
+func TestVarint(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		value   uint64
+		encoded data.Bytes
+	}{
+		// single byte
+		{0, data.Bytes{0x00}},
+		{1, data.Bytes{0x01}},
+		{127, data.Bytes{0x7f}},
+		// multi-byte, the protobuf docs example
+		{128, data.Bytes{0x80, 0x01}},
+		{150, data.Bytes{0x96, 0x01}},
+		{300, data.Bytes{0xac, 0x02}},
+		{1<<64 - 1, data.Bytes{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(tc.encoded, data.AppendVarint(nil, tc.value), "%d", tc.value)
+
+		// with a prefix, and data after it
+		buf := data.AppendVarint(data.Bytes{0xaa}, tc.value)
+		buf = append(buf, 0xbb)
+		v, n, err := buf[1:].Varint()
+		if assert.Nil(err, "%d: %+v", tc.value, err) {
+			assert.Equal(tc.value, v)
+			assert.Equal(len(tc.encoded), n)
+			assert.Equal(data.Bytes{0xbb}, buf[1+n:])
+		}
+	}
+
+	// appends in place, like the builtin
+	buf := make(data.Bytes, 1, 16)
+	res := data.AppendVarint(buf, 300)
+	assert.Equal(data.Bytes{0x00, 0xac, 0x02}, res)
+	assert.Equal(&buf[0], &res[0])
+
+	errs := []data.Bytes{
+		nil,
+		{},
+		{0x80},                         // truncated
+		{0xac, 0x82},                   // truncated
+		bytes.Repeat([]byte{0xff}, 11), // overflow
+		{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x02},       // overflow in the 10th byte
+		{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00}, // too long
+	}
+	for i, input := range errs {
+		_, n, err := input.Varint()
+		assert.NotNil(err, "%d", i)
+		assert.Equal(0, n, "%d", i)
+	}
+}
+
+func TestBytesSlice(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	var many []data.Bytes
+	for i := 0; i < 200; i++ {
+		many = append(many, data.Bytes(bytes.Repeat([]byte{byte(i)}, i)))
+	}
+	cases := []struct {
+		items    []data.Bytes
+		expected []byte
+	}{
+		{[]data.Bytes{}, []byte{0x00}},
+		{[]data.Bytes{{0x1a, 0x2b}}, []byte{0x01, 0x02, 0x1a, 0x2b}},
+		{[]data.Bytes{{}, {0x1a}}, []byte{0x02, 0x00, 0x01, 0x1a}},
+		{many, nil},
+	}
+
+	for i, tc := range cases {
+		d := data.MarshalBytesSlice(tc.items)
+		if tc.expected != nil {
+			assert.Equal(tc.expected, d, "%d", i)
+		}
+		res, err := data.UnmarshalBytesSlice(d)
+		require.Nil(err, "%d: %+v", i, err)
+		assert.Equal(tc.items, res, "%d", i)
+	}
+
+	// nil packs like an empty slice
+	res, err := data.UnmarshalBytesSlice(data.MarshalBytesSlice(nil))
+	require.Nil(err, "%+v", err)
+	assert.Equal([]data.Bytes{}, res)
+
+	errs := [][]byte{
+		nil,
+		{0x01},                         // missing item
+		{0x02, 0x01, 0x1a},             // missing item
+		{0x01, 0x03, 0x1a, 0x2b},       // truncated item
+		{0x01, 0x01, 0x1a, 0x2b},       // trailing bytes
+		{0xff, 0xff, 0xff, 0xff, 0x0f}, // huge count
+		{0x80},                         // truncated count
+	}
+	for i, input := range errs {
+		_, err := data.UnmarshalBytesSlice(input)
+		assert.NotNil(err, "%d", i)
+	}
+}
@@ -0,0 +1,114 @@
+package data
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+var bytesType = reflect.TypeOf(Bytes(nil))
+
+// WalkBytes calls fn for every Bytes value reachable from v: in exported
+// struct fields, nested structs, slices, arrays, maps, pointers and
+// interfaces. It stops at the first error fn returns, and returns it.
+//
+// The path names the value the way it would be written in go, relative to
+// v, eg. "Keys[2].Pub" or "Meta[owner]". Map entries are visited in key
+// order. Nil pointers are skipped, and v must not contain cycles.
+//
+// This allows generic validation, for example rejecting empty fields:
+//
+//	err := data.WalkBytes(msg, func(path string, b data.Bytes) error {
+//		if len(b) == 0 {
+//			return errors.Errorf("%s is empty", path)
+//		}
+//		return nil
+//	})
+func WalkBytes(v interface{}, fn func(path string, b Bytes) error) error {
+	return walkBytes(reflect.ValueOf(v), "", fn)
+}
+
+// ToHexFields returns all Bytes values in the struct v (or pointer to one)
+// as uppercase hex, keyed by their path as in WalkBytes, eg. for metrics
+// labels or structured logging:
+//
+//	{"ID": "01", "Keys[0].Pub": "02AB", "Keys[1].Pub": ""}
+//
+// Nil and empty values map to "". It returns an error if v is not a struct.
+func ToHexFields(v interface{}) (map[string]string, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.Errorf("Expected a struct, got %T", v)
+	}
+	res := map[string]string{}
+	err := walkBytes(rv, "", func(path string, b Bytes) error {
+		res[path] = b.HexGrouped(0, "")
+		return nil
+	})
+	return res, err
+}
+
+func walkBytes(v reflect.Value, path string, fn func(string, Bytes) error) error {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Type() == bytesType {
+		return fn(path, v.Interface().(Bytes))
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return walkBytes(v.Elem(), path, fn)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue // unexported
+			}
+			err := walkBytes(v.Field(i), joinPath(path, f.Name), fn)
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			err := walkBytes(v.Index(i), fmt.Sprintf("%s[%d]", path, i), fn)
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		type entry struct {
+			key string
+			val reflect.Value
+		}
+		entries := make([]entry, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			entries = append(entries, entry{fmt.Sprint(k.Interface()), v.MapIndex(k)})
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+		for _, e := range entries {
+			err := walkBytes(e.val, path+"["+e.key+"]", fn)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
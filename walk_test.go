@@ -0,0 +1,164 @@
+package data_test
+
+import (
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type WKey struct {
+	Pub  data.Bytes
+	Name string
+}
+
+type WMsg struct {
+	ID     data.Bytes
+	Keys   []WKey
+	Hashes []data.Bytes
+	Owner  *WKey
+	Extra  interface{}
+	Meta   map[string]data.Bytes
+	Raw    []byte
+	secret data.Bytes
+}
+
+func TestWalkBytes(t *testing.T) {
+	assert := assert.New(t)
+
+	msg := WMsg{
+		ID:     data.Bytes{1},
+		Keys:   []WKey{{Pub: data.Bytes{2}}, {Pub: nil}},
+		Hashes: []data.Bytes{{3}, {4, 5}},
+		Owner:  &WKey{Pub: data.Bytes{6}},
+		Extra:  &WKey{Pub: data.Bytes{7}},
+		Meta:   map[string]data.Bytes{"z": {9}, "a": {8}},
+		Raw:    []byte{10},
+		secret: data.Bytes{11},
+	}
+
+	cases := []struct {
+		value    interface{}
+		expected map[string]data.Bytes
+	}{
+		{msg, map[string]data.Bytes{
+			"ID":          {1},
+			"Keys[0].Pub": {2},
+			"Keys[1].Pub": nil,
+			"Hashes[0]":   {3},
+			"Hashes[1]":   {4, 5},
+			"Owner.Pub":   {6},
+			"Extra.Pub":   {7},
+			"Meta[a]":     {8},
+			"Meta[z]":     {9},
+		}},
+		// pointers and nil pointers
+		{&msg, nil},
+		{&WMsg{ID: data.Bytes{1}}, map[string]data.Bytes{"ID": {1}}},
+		{(*WMsg)(nil), map[string]data.Bytes{}},
+		{nil, map[string]data.Bytes{}},
+		// top-level values
+		{data.Bytes{1, 2}, map[string]data.Bytes{"": {1, 2}}},
+		{[]data.Bytes{{1}}, map[string]data.Bytes{"[0]": {1}}},
+		{"no bytes", map[string]data.Bytes{}},
+	}
+	cases[1].expected = cases[0].expected
+
+	for i, tc := range cases {
+		found := map[string]data.Bytes{}
+		err := data.WalkBytes(tc.value, func(path string, b data.Bytes) error {
+			found[path] = b
+			return nil
+		})
+		assert.Nil(err, "%d: %+v", i, err)
+		assert.Equal(tc.expected, found, "%d", i)
+	}
+
+	// order is stable
+	var paths []string
+	err := data.WalkBytes(msg, func(path string, b data.Bytes) error {
+		paths = append(paths, path)
+		return nil
+	})
+	assert.Nil(err, "%+v", err)
+	assert.Equal([]string{"ID", "Keys[0].Pub", "Keys[1].Pub", "Hashes[0]", "Hashes[1]",
+		"Owner.Pub", "Extra.Pub", "Meta[a]", "Meta[z]"}, paths)
+
+	// stops at the first error
+	var visited []string
+	err = data.WalkBytes(msg, func(path string, b data.Bytes) error {
+		visited = append(visited, path)
+		if len(b) == 0 {
+			return errors.Errorf("%s is empty", path)
+		}
+		return nil
+	})
+	if assert.NotNil(err) {
+		assert.Equal("Keys[1].Pub is empty", err.Error())
+	}
+	assert.Equal([]string{"ID", "Keys[0].Pub", "Keys[1].Pub"}, visited)
+}
+
+func TestToHexFields(t *testing.T) {
+	assert := assert.New(t)
+
+	msg := WMsg{
+		ID:     data.Bytes{0x01},
+		Keys:   []WKey{{Pub: data.Bytes{0x02, 0xab}}, {Pub: nil}},
+		Hashes: []data.Bytes{{0xde, 0xad}, {}},
+		Owner:  &WKey{Pub: data.Bytes{0xbe, 0xef}, Name: "owner"},
+		Meta:   map[string]data.Bytes{"b": {0x1a, 0x2b}, "a": {0xff}},
+		Raw:    []byte{0x10},
+		secret: data.Bytes{0x11},
+	}
+	expected := map[string]string{
+		"ID":          "01",
+		"Keys[0].Pub": "02AB",
+		"Keys[1].Pub": "",
+		"Hashes[0]":   "DEAD",
+		"Hashes[1]":   "",
+		"Owner.Pub":   "BEEF",
+		"Meta[a]":     "FF",
+		"Meta[b]":     "1A2B",
+	}
+
+	for _, v := range []interface{}{msg, &msg} {
+		fields, err := data.ToHexFields(v)
+		if assert.Nil(err, "%+v", err) {
+			assert.Equal(expected, fields)
+		}
+	}
+
+	// deeper nesting
+	type outer struct {
+		Inner struct {
+			Msgs []WMsg
+		}
+		Tag data.Bytes
+	}
+	var o outer
+	o.Tag = data.Bytes{0x07}
+	o.Inner.Msgs = []WMsg{{}, {Owner: &WKey{Pub: data.Bytes{0xc0, 0xde}}}}
+	fields, err := data.ToHexFields(o)
+	if assert.Nil(err, "%+v", err) {
+		assert.Equal(map[string]string{
+			"Inner.Msgs[0].ID":        "",
+			"Inner.Msgs[1].ID":        "",
+			"Inner.Msgs[1].Owner.Pub": "C0DE",
+			"Tag":                     "07",
+		}, fields)
+	}
+
+	// no bytes at all
+	fields, err = data.ToHexFields(struct{ Name string }{"x"})
+	if assert.Nil(err, "%+v", err) {
+		assert.Equal(map[string]string{}, fields)
+	}
+
+	// only structs
+	for _, v := range []interface{}{nil, data.Bytes{1}, []WKey{}, "x", (*WMsg)(nil)} {
+		_, err := data.ToHexFields(v)
+		assert.NotNil(err, "%#v", v)
+	}
+}
@@ -0,0 +1,120 @@
+package data
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// WordEncoder encodes bytes as a space-separated list of words, for
+// fingerprints that operators can read out and compare.
+//
+// The word list must have a power of two entries (between 2 and 65536),
+// and each word stands for that many bits of the input, so with 256 words
+// every byte is one word. Create it with NewWordEncoder, the zero value
+// has no words and fails to marshal or unmarshal anything.
+type WordEncoder struct {
+	words []string
+	index map[string]uint32
+	bits  uint
+}
+
+// NewWordEncoder returns a WordEncoder with the given word list.
+//
+// It returns an error if the length of the list is not a power of two,
+// or if any word is empty, contains whitespace or is duplicated.
+func NewWordEncoder(words []string) (WordEncoder, error) {
+	n := len(words)
+	if n < 2 || n > 1<<16 || n&(n-1) != 0 {
+		return WordEncoder{}, errors.Errorf("Word list length must be a power of two, got %d", n)
+	}
+	e := WordEncoder{
+		words: append([]string(nil), words...),
+		index: make(map[string]uint32, n),
+	}
+	for 1<<e.bits < n {
+		e.bits++
+	}
+	for i, w := range words {
+		if w == "" || len(strings.Fields(w)) != 1 || strings.TrimSpace(w) != w {
+			return WordEncoder{}, errors.Errorf("Invalid word: %q", w)
+		}
+		if _, ok := e.index[w]; ok {
+			return WordEncoder{}, errors.Errorf("Duplicate word: %s", w)
+		}
+		e.index[w] = uint32(i)
+	}
+	return e, nil
+}
+
+func (e WordEncoder) _assertByteEncoder() ByteEncoder {
+	return e
+}
+
+// checkWords rejects the zero value, which has no word list
+func (e WordEncoder) checkWords() error {
+	if e.bits == 0 {
+		return errors.New("WordEncoder without words, use NewWordEncoder")
+	}
+	return nil
+}
+
+func (e WordEncoder) Unmarshal(dst *[]byte, src []byte) (err error) {
+	if err := e.checkWords(); err != nil {
+		return err
+	}
+	s, err := ParseJSONString(src)
+	if err != nil {
+		return err
+	}
+	fields := strings.Fields(s)
+	total := uint(len(fields)) * e.bits
+	// the padding in the last word must be less than a byte
+	if total%8 >= e.bits {
+		return errors.Errorf("Invalid number of words: %d", len(fields))
+	}
+	res := make([]byte, 0, total/8)
+	var acc uint32
+	var n uint
+	for _, w := range fields {
+		idx, ok := e.index[w]
+		if !ok {
+			return errors.Errorf("Unknown word: %s", w)
+		}
+		acc = acc<<e.bits | idx
+		n += e.bits
+		for n >= 8 {
+			n -= 8
+			res = append(res, byte(acc>>n))
+		}
+		acc &= 1<<n - 1
+	}
+	if acc != 0 {
+		return errors.New("Non-zero padding in last word")
+	}
+	*dst = res
+	return nil
+}
+
+func (e WordEncoder) Marshal(bytes []byte) ([]byte, error) {
+	if err := e.checkWords(); err != nil {
+		return nil, err
+	}
+	words := make([]string, 0, (uint(len(bytes))*8+e.bits-1)/e.bits)
+	var acc uint32
+	var n uint
+	for _, b := range bytes {
+		acc = acc<<8 | uint32(b)
+		n += 8
+		for n >= e.bits {
+			n -= e.bits
+			words = append(words, e.words[acc>>n])
+			acc &= 1<<n - 1
+		}
+	}
+	if n > 0 {
+		words = append(words, e.words[acc<<(e.bits-n)])
+	}
+	return json.Marshal(strings.Join(words, " "))
+}
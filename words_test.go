@@ -0,0 +1,100 @@
+package data_test
+
+import (
+	"fmt"
+	"testing"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var hexWords = []string{
+	"zero", "one", "two", "three", "four", "five", "six", "seven",
+	"eight", "nine", "alpha", "bravo", "charlie", "delta", "echo", "foxtrot",
+}
+
+func TestWordEncoder(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	// 4 bits per word
+	small, err := data.NewWordEncoder(hexWords)
+	require.Nil(err, "%+v", err)
+	d, err := small.Marshal([]byte{0x1a, 0xf0})
+	require.Nil(err, "%+v", err)
+	assert.Equal(`"one alpha foxtrot zero"`, string(d))
+
+	// 1, 3 (leaves padding), 8 and 11 bits per word
+	var many []string
+	for i := 0; i < 2048; i++ {
+		many = append(many, fmt.Sprintf("w%d", i))
+	}
+	encoders := []data.WordEncoder{small}
+	for _, n := range []int{2, 8, 256, 2048} {
+		enc, err := data.NewWordEncoder(many[:n])
+		require.Nil(err, "%d: %+v", n, err)
+		encoders = append(encoders, enc)
+	}
+
+	inputs := [][]byte{
+		{},
+		{0x00},
+		{0xde, 0xad, 0xbe, 0xef},
+		[]byte("fingerprint"),
+	}
+	for i, enc := range encoders {
+		for _, input := range inputs {
+			d, err := enc.Marshal(input)
+			require.Nil(err, "%d: %+v", i, err)
+			var output []byte
+			err = enc.Unmarshal(&output, d)
+			require.Nil(err, "%d %s: %+v", i, d, err)
+			assert.Equal(input, output, "%d", i)
+		}
+	}
+
+	// extra whitespace is fine
+	var output []byte
+	err = small.Unmarshal(&output, []byte(`" one  alpha\tfoxtrot zero "`))
+	require.Nil(err, "%+v", err)
+	assert.Equal([]byte{0x1a, 0xf0}, output)
+
+	errs := []string{
+		`"one alpha foxtrot golf"`, // unknown word
+		`"One alpha"`,              // case sensitive
+		`"one alpha foxtrot"`,      // length mismatch
+		`"one"`,                    // length mismatch
+		`0123`,                     // not in quotes
+	}
+	for _, input := range errs {
+		err := small.Unmarshal(&output, []byte(input))
+		assert.NotNil(err, input)
+	}
+
+	// 2048 words are 11 bits each, so 1 byte needs 1 word with 3 bits of padding
+	words := encoders[len(encoders)-1]
+	assert.NotNil(words.Unmarshal(&output, []byte(`"w1"`)), "non-zero padding")
+	assert.Nil(words.Unmarshal(&output, []byte(`"w8"`)))
+	assert.Equal([]byte{0x01}, output)
+
+	// invalid word lists
+	invalid := [][]string{
+		nil,
+		{"one"},
+		hexWords[:10],
+		{"one", "one"},
+		{"one", ""},
+		{"one", "two words"},
+	}
+	for _, list := range invalid {
+		_, err := data.NewWordEncoder(list)
+		assert.NotNil(err, "%v", list)
+	}
+
+	// the zero value fails instead of panicking
+	var zero data.WordEncoder
+	_, err = zero.Marshal([]byte{1, 2})
+	assert.NotNil(err)
+	assert.NotNil(zero.Unmarshal(&output, []byte(`"w1 w2"`)))
+	assert.NotNil(zero.Unmarshal(&output, []byte(`""`)))
+}
@@ -0,0 +1,77 @@
+/*
+Package zerowidth is an experimental ByteEncoder that hides bytes in text.
+
+Every byte becomes four invisible characters (zero width space, non-joiner,
+joiner and word joiner, two bits each), so the encoded value can be
+embedded in other text without showing up. This is for research, do not use
+it to store data.
+*/
+package zerowidth
+
+import (
+	"encoding/json"
+	"strings"
+
+	data "github.com/neatio-net/data-go"
+	"github.com/pkg/errors"
+)
+
+// Encoder encodes bytes as zero-width unicode characters
+var Encoder data.ByteEncoder = zeroWidthEncoder{}
+
+// symbols are the characters for the values 0 to 3
+var symbols = []rune{'\u200b', '\u200c', '\u200d', '\u2060'}
+
+// zeroWidthEncoder implements ByteEncoder with 2 bits per character,
+// most significant first
+type zeroWidthEncoder struct{}
+
+func (e zeroWidthEncoder) _assertByteEncoder() data.ByteEncoder {
+	return e
+}
+
+func (_ zeroWidthEncoder) Unmarshal(dst *[]byte, src []byte) error {
+	s, err := data.ParseJSONString(src)
+	if err != nil {
+		return err
+	}
+	res := make([]byte, 0, len(s)/12)
+	var acc byte
+	n := 0
+	for _, r := range s {
+		v := symbolValue(r)
+		if v < 0 {
+			return errors.Errorf("Encountered visible character: %q", r)
+		}
+		acc = acc<<2 | byte(v)
+		n++
+		if n == 4 {
+			res = append(res, acc)
+			acc, n = 0, 0
+		}
+	}
+	if n != 0 {
+		return errors.Errorf("Truncated input: %d extra characters", n)
+	}
+	*dst = res
+	return nil
+}
+
+func (_ zeroWidthEncoder) Marshal(bytes []byte) ([]byte, error) {
+	var s strings.Builder
+	for _, b := range bytes {
+		for shift := 6; shift >= 0; shift -= 2 {
+			s.WriteRune(symbols[b>>uint(shift)&3])
+		}
+	}
+	return json.Marshal(s.String())
+}
+
+func symbolValue(r rune) int {
+	for i, s := range symbols {
+		if r == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,51 @@
+package zerowidth_test
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/neatio-net/data-go/zerowidth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoder(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	enc := zerowidth.Encoder
+	inputs := [][]byte{
+		{},
+		{0x00},
+		{0xff, 0x1b},
+		[]byte("hidden message"),
+	}
+
+	for _, input := range inputs {
+		d, err := enc.Marshal(input)
+		require.Nil(err, "%+v", err)
+		// 4 characters per byte, plus quotes
+		assert.Equal(4*len(input)+2, utf8.RuneCount(d))
+
+		var output []byte
+		err = enc.Unmarshal(&output, d)
+		require.Nil(err, "%+v", err)
+		assert.Equal(input, output)
+	}
+
+	d, err := enc.Marshal([]byte{0x1b})
+	require.Nil(err, "%+v", err)
+	assert.Equal("\"\u200b\u200c\u200d\u2060\"", string(d))
+
+	errs := []string{
+		"\"\u200b\u200c\u200dx\"",       // visible character
+		"\"a\u200b\u200c\u200d\u2060\"", // visible character
+		"\"\u200b\u200c \u200d\u2060\"", // space is visible
+		"\"\u200b\u200c\u200d\"",        // truncated
+		`0123`,                          // not in quotes
+	}
+	for _, input := range errs {
+		var output []byte
+		err := enc.Unmarshal(&output, []byte(input))
+		assert.NotNil(err, "%q", input)
+	}
+}